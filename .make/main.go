@@ -6,6 +6,7 @@ import (
 	"os"
 
 	. "github.com/anchore/go-make"
+	"github.com/anchore/go-make/config"
 	"github.com/anchore/go-make/file"
 	"github.com/anchore/go-make/run"
 	"github.com/anchore/go-make/tasks/golint"
@@ -30,7 +31,6 @@ func main() {
 			Description: "run integration tests in Docker",
 			Run: func() {
 				dockerfile := "git/testdata/Dockerfile"
-				cwd := file.Cwd()
 
 				// use dockerfile content hash as image tag for cache busting
 				dockerfileContent := file.Read(dockerfile)
@@ -39,14 +39,20 @@ func main() {
 				image := "go-make-integration-test:" + tag
 
 				// build image if needed (rebuilds when Dockerfile changes)
-				if Run("docker images -q "+image, run.Quiet()) == "" {
+				engine := config.ContainerEngine
+				if Run(engine+" images -q "+image, run.Quiet()) == "" {
 					Log("building Docker image %q...", image)
-					Run("docker build -t " + image + " -f " + dockerfile + " .")
+					Run(engine + " build -t " + image + " -f " + dockerfile + " .")
 				}
 
-				// run all integration tests in container
+				// run all integration tests in container, as the image's root user which owns the go caches
 				Log("running integration tests in Docker...")
-				Run("docker run --pull never --rm -t -v "+cwd+":/app -w /app -e IN_DOCKER=true "+image+" go test -v -tags=integration -run TestIntegration ./git/...", run.Stdout(os.Stdout), run.Stderr(os.Stderr))
+				Run("go test -v -tags=integration -run TestIntegration ./git/...",
+					run.InContainer(image, run.User(""), run.EngineArgs("--pull", "never", "-t")),
+					run.Env("IN_DOCKER", "true"),
+					run.Stdout(os.Stdout),
+					run.Stderr(os.Stderr),
+				)
 			},
 		}.RunOn("test"),
 	)
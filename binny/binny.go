@@ -2,6 +2,7 @@ package binny
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -30,11 +31,17 @@ const CMD = "binny"
 // LocalModule is non-empty, this entry refers to a checked-out source tree on
 // disk (method: go-install + with.module: <relative path>) — go-make will shim
 // it to `go run` instead of fetching or building a release binary. LocalModule
-// is an absolute path; Entrypoint mirrors binny's with.entrypoint.
+// is an absolute path; Entrypoint mirrors binny's with.entrypoint. Method and With are
+// the install method and its parameters, VersionMethod and VersionWith the method
+// resolving the latest version and its parameters, used to install the tool without binny.
 type toolSpec struct {
-	Version     string
-	LocalModule string
-	Entrypoint  string
+	Version       string
+	LocalModule   string
+	Entrypoint    string
+	Method        string
+	With          map[string]any
+	VersionMethod string
+	VersionWith   map[string]any
 }
 
 var (
@@ -100,8 +107,38 @@ func ManagedToolPath(cmd string) string {
 	return ""
 }
 
-// Install installs the named executable and returns an absolute path to it
+// Install installs the named executable and returns an absolute path to it. Tools are installed
+// without the binny executable when their .binny.yaml configuration is supported, falling back to
+// binny otherwise, or when config.BinnyInstall is set. Tools in the lock file are never installed
+// with binny, which would not verify them: failing installs panic, as does config.BinnyInstall.
 func Install(cmd string) string {
+	locked := cmd != CMD && isLocked(cmd)
+	if locked && config.BinnyInstall {
+		panic(fmt.Errorf("%v is locked in %v and would not be verified when installed with binny; unset GOMAKE_BINNY_INSTALL, or disable the lock file with GOMAKE_TOOL_LOCK=false", cmd, lockFile()))
+	}
+	if cmd != CMD && !config.BinnyInstall {
+		toolPath := ToolPath(cmd)
+		err := lang.Catch(func() {
+			installTool(cmd, toolPath)
+		})
+		if err == nil {
+			return toolPath
+		}
+		if locked || errors.Is(err, fetch.ErrChecksumMismatch) {
+			// never fall back to an unverified install
+			panic(err)
+		}
+		if errors.Is(err, errUnsupported) {
+			log.Debug("installing %v with binny: %v", cmd, err)
+		} else {
+			log.Warn("unable to install %v, installing with binny: %v", cmd, err)
+		}
+	}
+	return installWithBinny(cmd)
+}
+
+// installWithBinny installs the binny executable, then the named executable using binny
+func installWithBinny(cmd string) string {
 	binnyPath := ToolPath(CMD)
 	binnySpec := binnyManaged[CMD]
 	if installed[CMD] != binnyPath {
@@ -150,15 +187,22 @@ func Install(cmd string) string {
 	toolPath := ToolPath(cmd)
 	toolDir := filepath.Dir(toolPath)
 
-	out := bytes.Buffer{}
-	lang.Return(run.Command(binnyPath, run.Options(cfg...), run.Args("install", cmd),
+	alreadyInstalled := false
+	result := run.CommandResult(binnyPath, run.Options(cfg...), run.Args("install", cmd),
 		run.Env("BINNY_LOG_LEVEL", "info"),
 		run.Env("BINNY_ROOT", toolDir),
 		run.Quiet(),
-		run.Stderr(&out),
-	))
+		run.Stderr(io.Discard),
+		run.OnLine(func(_, line string) {
+			if strings.Contains(line, "already installed") {
+				alreadyInstalled = true
+			}
+			log.Trace("binny: %s", line)
+		}),
+	)
+	lang.Throw(result.Err)
 
-	if !strings.Contains(out.String(), "already installed") {
+	if !alreadyInstalled {
 		// check if binny has given us an executable without .exe on windows and copy it, if so
 		nonExe := filepath.Join(toolDir, cmd)
 		if config.Windows && nonExe != toolPath && file.Exists(nonExe) {
@@ -169,7 +213,7 @@ func Install(cmd string) string {
 			}))
 		}
 		log.Info("binny installed: %v at %v", cmd, toolPath)
-		log.Debug("    └─ output: %v", out.String())
+		log.Debug("    └─ output: %v", result.Stderr)
 	}
 
 	return toolPath
@@ -236,7 +280,7 @@ func shellQuote(s string) string {
 }
 
 func installBinny(binnyPath, version string) {
-	err := fetch.BinaryRelease(binnyPath, fetch.ReleaseSpec{
+	spec := fetch.ReleaseSpec{
 		URL: "https://github.com/anchore/binny/releases/download/v{{.version}}/binny_{{.version}}_{{.os}}_{{.arch}}.{{.ext}}",
 		Args: map[string]string{
 			"ext":     "tar.gz",
@@ -247,7 +291,16 @@ func installBinny(binnyPath, version string) {
 				"ext": "zip",
 			},
 		},
-	})
+	}
+	artifact, locked := readLock().artifact(CMD, version)
+	if locked {
+		spec = fetch.ReleaseSpec{URL: artifact.URL, SHA256: artifact.SHA256}
+	}
+	err := fetch.BinaryRelease(binnyPath, spec)
+	if err != nil && (locked || errors.Is(err, fetch.ErrChecksumMismatch)) {
+		// building from source would install an unverified binny
+		panic(err)
+	}
 
 	if err != nil {
 		log.Error(err)
@@ -267,7 +320,12 @@ func installBinny(binnyPath, version string) {
 }
 
 func readRootBinnyYaml() map[string]toolSpec {
-	rootDir := template.Render(config.RootDir)
+	var rootDir string
+	if err := lang.Catch(func() { rootDir = template.Render(config.RootDir) }); err != nil {
+		// go-make may be imported outside of a repository, such as by cmd/init
+		log.Debug("no root directory for .binny.yaml: %v", err)
+		return map[string]toolSpec{}
+	}
 	binnyYaml := file.FindParent(rootDir, ".binny.yaml")
 	if binnyYaml == "" {
 		log.Debug("no .binny.yaml found in %v or any parent directory", rootDir)
@@ -311,10 +369,18 @@ func readBinnyYamlSpecs(binnyConfig io.Reader, baseDir string) map[string]toolSp
 // "go-install" and `with.module` looks like a filesystem path (starts with
 // "." or is absolute) — the same shape used for self-development of binny.
 func toSpec(m map[string]any, baseDir string) toolSpec {
-	spec := toolSpec{Version: extractVersion(m["version"])}
-
 	method := toString(m["method"])
 	with, _ := m["with"].(map[string]any)
+	version, _ := m["version"].(map[string]any)
+	versionWith, _ := version["with"].(map[string]any)
+	spec := toolSpec{
+		Version:       extractVersion(m["version"]),
+		Method:        method,
+		With:          with,
+		VersionMethod: toString(version["method"]),
+		VersionWith:   versionWith,
+	}
+
 	module := toString(with["module"])
 	if method == "go-install" && isLocalPath(module) && baseDir != "" {
 		spec.LocalModule = lang.Return(filepath.Abs(filepath.Join(baseDir, module)))
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/file"
 	"github.com/anchore/go-make/lang"
 	"github.com/anchore/go-make/require"
 	"github.com/anchore/go-make/template"
@@ -109,6 +110,14 @@ func Test_readBinnyYamlSpecs_localModule(t *testing.T) {
 	require.Equal(t, "v0.9.0", specs["chronicle"].Version)
 }
 
+func Test_readRootBinnyYaml_noRoot(t *testing.T) {
+	// go-make is imported outside of a repository by cmd/init
+	require.SetAndRestore(t, &config.RootDir, "{{GitRoot}}")
+	file.InDir(t.TempDir(), func() {
+		require.Equal(t, map[string]toolSpec{}, readRootBinnyYaml())
+	})
+}
+
 func Test_isLocalPath(t *testing.T) {
 	tests := []struct {
 		in   string
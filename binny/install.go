@@ -0,0 +1,443 @@
+package binny
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/module"
+
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/fetch"
+	"github.com/anchore/go-make/file"
+	"github.com/anchore/go-make/lang"
+	"github.com/anchore/go-make/log"
+	"github.com/anchore/go-make/redact"
+	"github.com/anchore/go-make/run"
+	"github.com/anchore/go-make/template"
+)
+
+var (
+	// githubAPI is the GitHub API used to find release assets
+	githubAPI = "https://api.github.com"
+
+	// goProxy is the Go module proxy used to find the latest version of modules, from GOPROXY
+	goProxy = goProxyURL(config.Env("GOPROXY", ""))
+)
+
+// errUnsupported indicates a tool configuration only the binny executable installs
+var errUnsupported = errors.New("not supported without binny")
+
+// installTool installs the named tool as configured in .binny.yaml without the binny
+// executable, supporting the github-release, go-install and hosted-shell install methods
+// and the go-proxy version method. Tools already installed at the requested version are
+// left as-is. Panics with errUnsupported for other configurations.
+func installTool(name, toolPath string) {
+	spec := findSpec(name)
+	installedVersion := installedVersions()[name]
+	if !isLocalSpec(spec) && file.Exists(toolPath) && versionInstalled(spec.Version, installedVersion) {
+		log.Debug("already installed: %v %v", name, installedVersion)
+		return
+	}
+
+	lang.Throw(os.MkdirAll(filepath.Dir(toolPath), 0o755))
+
+	version := spec.Version
+	if isLatest(version) && !isLocalSpec(spec) {
+		// the lock file pins the latest version
+		version = lang.Default(lockedVersion(name), latestVersion(spec))
+	}
+	switch spec.Method {
+	case "github-release":
+		installGitHubRelease(name, spec, version, toolPath)
+	case "go-install":
+		version = installGoModule(name, spec, version, toolPath)
+	case "hosted-shell":
+		installHostedShell(name, spec, version, toolPath)
+	default:
+		panic(fmt.Errorf("install method %q: %w", spec.Method, errUnsupported))
+	}
+
+	recordInstalled(name, version)
+	log.Info("installed: %v %v at %v", name, version, toolPath)
+}
+
+// findSpec returns the tool configuration, from the local .binny.yaml or the embedded defaults
+func findSpec(name string) toolSpec {
+	if inLocalConfig(name) {
+		return binnyManaged[name]
+	}
+	return defaultSpecs[name]
+}
+
+// versionInstalled indicates the installed version satisfies the requested version, where
+// latest is satisfied by any version; updates are made with binny:update
+func versionInstalled(requested, installed string) bool {
+	if installed == "" {
+		return false
+	}
+	return isLatest(requested) || matchesVersion(requested, installed)
+}
+
+func isLatest(version string) bool {
+	return version == "" || version == "latest"
+}
+
+// latestVersion resolves the latest version of the tool, with the go-proxy version method
+// or the install method
+func latestVersion(spec toolSpec) string {
+	switch {
+	case spec.VersionMethod == "go-proxy":
+		return latestModuleVersion(lang.Default(toString(spec.VersionWith["module"]), toString(spec.With["module"])))
+	case spec.Method == "github-release":
+		return fetchGitHubRelease(spec, "latest").TagName
+	case spec.Method == "go-install":
+		return latestModuleVersion(toString(spec.With["module"]))
+	}
+	panic(fmt.Errorf("resolving the latest version with %q: %w", lang.Default(spec.VersionMethod, spec.Method), errUnsupported))
+}
+
+// githubRelease is the subset of the GitHub release API response used to find assets
+type githubRelease struct {
+	TagName string               `json:"tag_name"`
+	Assets  []githubReleaseAsset `json:"assets"`
+}
+
+type githubReleaseAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+	// Digest is the checksum GitHub computed for the asset, e.g. sha256:<hex>, missing for older releases
+	Digest string `json:"digest"`
+}
+
+func (r githubRelease) assetNames() []string {
+	names := make([]string, len(r.Assets))
+	for i, asset := range r.Assets {
+		names[i] = asset.Name
+	}
+	return names
+}
+
+// sha256 returns the hex SHA-256 checksum of the asset from its digest, empty when unknown
+func (a githubReleaseAsset) sha256() string {
+	checksum, _ := strings.CutPrefix(a.Digest, "sha256:")
+	if checksum == a.Digest {
+		return ""
+	}
+	return checksum
+}
+
+// installGitHubRelease downloads the release asset for this platform from the repository in
+// with.repo, the locked asset when in the lock file, verified against its checksum
+func installGitHubRelease(name string, spec toolSpec, version, toolPath string) {
+	if locked, ok := readLock().artifact(name, version); ok {
+		lang.Return(fetch.DownloadBinaryRelease(toolPath, fetch.ReleaseSpec{URL: locked.URL, SHA256: locked.SHA256}))
+		return
+	}
+
+	release := fetchGitHubRelease(spec, version)
+	toolName := strings.TrimSuffix(filepath.Base(toolPath), ".exe")
+	i := selectAsset(toolName, release.assetNames(), config.OS, config.Arch)
+	if i < 0 {
+		panic(fmt.Errorf("no %s release asset of %s %s in: %v: %w", currentPlatform(), spec.With["repo"], version, release.assetNames(), errUnsupported))
+	}
+
+	asset := release.Assets[i]
+	lang.Return(fetch.DownloadBinaryRelease(toolPath, fetch.ReleaseSpec{URL: asset.URL, SHA256: asset.sha256()}))
+}
+
+// fetchGitHubRelease returns the release of the repository in with.repo tagged version, or the
+// latest release
+func fetchGitHubRelease(spec toolSpec, version string) githubRelease {
+	repo := toString(spec.With["repo"])
+	if repo == "" {
+		panic(fmt.Errorf("github-release without with.repo: %w", errUnsupported))
+	}
+	releaseURL := fmt.Sprintf("%s/repos/%s/releases/tags/%s", githubAPI, repo, version)
+	if version == "latest" {
+		releaseURL = fmt.Sprintf("%s/repos/%s/releases/latest", githubAPI, repo)
+	}
+	var release githubRelease
+	lang.Throw(json.Unmarshal([]byte(lang.Return(fetch.Fetch(releaseURL, githubHeaders()))), &release))
+	return release
+}
+
+// githubHeaders authenticates GitHub API requests with GITHUB_TOKEN or GH_TOKEN, when set,
+// for higher rate limits; the token is registered to be masked in output
+func githubHeaders() fetch.Option {
+	headers := map[string]string{
+		"Accept":               "application/vnd.github+json",
+		"X-GitHub-Api-Version": "2022-11-28",
+	}
+	if token := lang.Default(config.Env("GITHUB_TOKEN", ""), config.Env("GH_TOKEN", "")); token != "" {
+		redact.Register(token)
+		headers["Authorization"] = "Bearer " + token
+	}
+	return fetch.Headers(headers)
+}
+
+var (
+	// osNames are the names release assets use for each GOOS
+	osNames = map[string][]string{
+		"darwin":  {"darwin", "macos", "mac", "apple", "osx"},
+		"linux":   {"linux"},
+		"windows": {"windows", "win", "win64"},
+		"freebsd": {"freebsd"},
+	}
+
+	// archNames are the names release assets use for each GOARCH, x86_64 is normalized to amd64
+	archNames = map[string][]string{
+		"amd64":   {"amd64", "x64", "64bit"},
+		"arm64":   {"arm64", "aarch64"},
+		"386":     {"386", "i386", "i686", "x86", "32bit"},
+		"arm":     {"arm", "armv6", "armv7", "armhf"},
+		"ppc64le": {"ppc64le"},
+		"s390x":   {"s390x"},
+		"riscv64": {"riscv64"},
+	}
+
+	// universalArchNames are used by macOS binaries for all architectures
+	universalArchNames = []string{"all", "universal"}
+
+	// nonExecutableSuffixes are release assets that are not an executable or a supported archive
+	nonExecutableSuffixes = []string{
+		".asc", ".apk", ".bundle", ".bz2", ".cdx", ".cert", ".crt", ".deb", ".dmg", ".json", ".jsonl",
+		".md", ".md5", ".msi", ".pem", ".pkg", ".pub", ".rpm", ".sbom", ".sha1", ".sha256", ".sha512",
+		".sig", ".snap", ".spdx", ".sum", ".txt", ".xml", ".xz", ".yaml", ".yml", ".zst", ".7z",
+	}
+
+	assetTokenSplitter = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// selectAsset returns the index of the release asset for the platform, preferring assets named
+// for the tool and the shortest name, e.g. cosign-linux-amd64 over cosign-linux-pivkey-amd64.
+// Returns -1 when no asset matches.
+func selectAsset(toolName string, names []string, goos, goarch string) int {
+	var candidates []int
+	for i, name := range names {
+		if assetMatches(name, goos, goarch) {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return -1
+	}
+	toolName = strings.ToLower(toolName)
+	slices.SortStableFunc(candidates, func(a, b int) int {
+		aNamed := strings.Contains(strings.ToLower(names[a]), toolName)
+		bNamed := strings.Contains(strings.ToLower(names[b]), toolName)
+		if aNamed != bNamed {
+			if aNamed {
+				return -1
+			}
+			return 1
+		}
+		return len(names[a]) - len(names[b])
+	})
+	return candidates[0]
+}
+
+// assetMatches indicates the release asset is an executable or archive for the platform
+func assetMatches(name, goos, goarch string) bool {
+	name = strings.ToLower(name)
+	for _, suffix := range nonExecutableSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return false
+		}
+	}
+	isArchive := strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz") || strings.HasSuffix(name, ".zip")
+	if goos == "windows" && !isArchive && !strings.HasSuffix(name, ".exe") {
+		return false
+	}
+
+	name = strings.NewReplacer("x86_64", "amd64", "x86-64", "amd64").Replace(name)
+	tokens := assetTokenSplitter.Split(name, -1)
+	if slices.Contains(tokens, "checksums") || slices.Contains(tokens, "sha256sums") {
+		return false
+	}
+	if !containsAny(tokens, osNames[goos]) {
+		return false
+	}
+	if containsAny(tokens, archNames[goarch]) {
+		// 386 names such as x86 may also be in amd64 names, e.g. x86-64 normalized to amd64
+		return goarch != "386" || !containsAny(tokens, archNames["amd64"])
+	}
+	for arch, names := range archNames {
+		if arch != goarch && containsAny(tokens, names) {
+			return false
+		}
+	}
+	return goos == "darwin" && containsAny(tokens, universalArchNames)
+}
+
+func containsAny(tokens, values []string) bool {
+	for _, value := range values {
+		if slices.Contains(tokens, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// installGoModule builds the package with.entrypoint of the module with.module: local modules
+// are built from source with go build, others installed with go install, which verifies modules
+// with the checksum database, returning the version
+func installGoModule(name string, spec toolSpec, version, toolPath string) string {
+	opts := []run.Option{run.Quiet()}
+	for _, env := range toStrings(spec.With["env"]) {
+		if key, value, ok := strings.Cut(env, "="); ok {
+			opts = append(opts, run.Env(key, value))
+		}
+	}
+
+	if isLocalSpec(spec) {
+		pkg := "./" + path.Clean(filepath.ToSlash(spec.Entrypoint))
+		opts = append(opts, run.InDir(spec.LocalModule), run.Args("build"),
+			ldflags(spec, "current"), run.Args("-o", toolPath, pkg))
+		lang.Return(run.Command("go", opts...))
+		return "current"
+	}
+
+	pkg := goPackage(spec)
+
+	// go install names the executable after the package, which may not be the tool name
+	binDir := lang.Return(os.MkdirTemp(filepath.Dir(toolPath), ".install-"))
+	defer func() {
+		log.Error(os.RemoveAll(binDir))
+	}()
+	opts = append(opts, run.Env("GOBIN", binDir), run.Args("install"),
+		ldflags(spec, version), run.Args(pkg+"@"+version))
+	lang.Return(run.Command("go", opts...))
+
+	entries := lang.Return(os.ReadDir(binDir))
+	if len(entries) != 1 {
+		panic(fmt.Errorf("expected go install %s@%s to create one executable, found %d", pkg, version, len(entries)))
+	}
+	moveExecutable(filepath.Join(binDir, entries[0].Name()), toolPath)
+	return version
+}
+
+// ldflags returns the with.ldflags option, templates rendered with the Version
+func ldflags(spec toolSpec, version string) run.Option {
+	flags := toStrings(spec.With["ldflags"])
+	for i, flag := range flags {
+		flags[i] = template.Render(flag, map[string]any{"Version": version})
+	}
+	if len(flags) == 0 {
+		return run.Options()
+	}
+	return run.LDFlags(flags...)
+}
+
+// latestModuleVersion returns the latest version of the module from the Go module proxy
+func latestModuleVersion(mod string) string {
+	escaped := lang.Return(module.EscapePath(mod))
+	var info struct {
+		Version string
+	}
+	lang.Throw(json.Unmarshal([]byte(lang.Return(fetch.Fetch(goProxy+"/"+escaped+"/@latest"))), &info))
+	if info.Version == "" {
+		panic(fmt.Errorf("no latest version of %s found in %s", mod, goProxy))
+	}
+	return info.Version
+}
+
+// goProxyURL returns the first proxy URL of a GOPROXY value, the default when none
+func goProxyURL(goproxy string) string {
+	for _, proxy := range strings.FieldsFunc(goproxy, func(r rune) bool { return r == ',' || r == '|' }) {
+		if strings.HasPrefix(proxy, "https://") || strings.HasPrefix(proxy, "http://") {
+			return strings.TrimSuffix(proxy, "/")
+		}
+	}
+	return "https://proxy.golang.org"
+}
+
+// installHostedShell runs the install script downloaded from with.url with the arguments in
+// with.args, templates using the {{ .Destination }} directory and {{ .Version }}, as binny does.
+// The script is verified against the lock file.
+func installHostedShell(name string, spec toolSpec, version, toolPath string) {
+	scriptURL := toString(spec.With["url"])
+	if scriptURL == "" || config.Windows {
+		panic(fmt.Errorf("hosted-shell: %w", errUnsupported))
+	}
+
+	dir := lang.Return(os.MkdirTemp(filepath.Dir(toolPath), ".install-"))
+	defer func() {
+		log.Error(os.RemoveAll(dir))
+	}()
+	script := filepath.Join(dir, "install.sh")
+	contents := []byte(lang.Return(fetch.Fetch(scriptURL)))
+	verifyLocked(name, version, contents)
+	lang.Throw(os.WriteFile(script, contents, 0o600))
+	destination := filepath.Join(dir, "bin")
+	lang.Throw(os.MkdirAll(destination, 0o755))
+
+	args := template.Render(toString(spec.With["args"]), map[string]any{
+		"Destination": destination,
+		"Version":     version,
+	})
+	lang.Return(run.Command("sh", run.Args("-c", "sh "+shellQuote(script)+" "+args), run.Quiet()))
+
+	var found string
+	lang.Throw(filepath.WalkDir(destination, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && d.Name() == name {
+			found = p
+			return filepath.SkipAll
+		}
+		return err
+	}))
+	if found == "" {
+		panic(fmt.Errorf("install script %s did not create %s", scriptURL, name))
+	}
+	moveExecutable(found, toolPath)
+}
+
+// moveExecutable moves an installed executable to toolPath, replacing an existing file
+func moveExecutable(src, toolPath string) {
+	// a previous release is read-only
+	if err := os.Remove(toolPath); err != nil && !os.IsNotExist(err) {
+		lang.Throw(err)
+	}
+	lang.Throw(os.Rename(src, toolPath))
+	lang.Throw(os.Chmod(toolPath, 0o755)) //nolint:gosec // needs execute permissions
+}
+
+// recordInstalled records the installed version of the tool in binny's state file, keeping the
+// entries of other tools as binny wrote them
+func recordInstalled(name, version string) {
+	statePath := filepath.Join(template.Render(config.ToolDir), stateFile)
+	state := map[string]any{}
+	if contents, err := os.ReadFile(statePath); err == nil {
+		log.Error(json.Unmarshal(contents, &state))
+	}
+	entries, _ := state["entries"].([]any)
+	entries = slices.DeleteFunc(entries, func(entry any) bool {
+		m, _ := entry.(map[string]any)
+		return toString(m["name"]) == name
+	})
+	state["entries"] = append(entries, map[string]any{"name": name, "version": version})
+	lang.Throw(os.WriteFile(statePath, lang.Return(json.MarshalIndent(state, "", "  ")), 0o600))
+}
+
+func toStrings(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var out []string
+		for _, value := range v {
+			if s := toString(value); s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+package binny
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/lang"
+	"github.com/anchore/go-make/redact"
+	"github.com/anchore/go-make/require"
+)
+
+func Test_installTool_githubRelease(t *testing.T) {
+	testToolDir(t)
+	require.SetAndRestore(t, &defaultSpecs, map[string]toolSpec{})
+	require.SetAndRestore(t, &binnyManaged, map[string]toolSpec{
+		"thething": {Version: "v1.2.3", Method: "github-release", With: map[string]any{"repo": "owner/thething"}},
+	})
+
+	toolPath := ToolPath("thething")
+	archive := require.Gzip(require.Tar(map[string][]byte{
+		"thething_1.2.3/" + filepath.Base(toolPath): []byte("v1.2.3 binary"),
+	}))
+	routes := map[string]any{}
+	serverURL := require.Server(t, routes)
+	require.SetAndRestore(t, &githubAPI, serverURL)
+
+	asset := fmt.Sprintf("thething_1.2.3_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+	routes["/repos/owner/thething/releases/tags/v1.2.3"] = map[string]any{
+		"tag_name": "v1.2.3",
+		"assets": []map[string]any{
+			{"name": "checksums.txt", "browser_download_url": serverURL + "/download/checksums.txt"},
+			{"name": asset, "browser_download_url": serverURL + "/download/" + asset},
+		},
+	}
+	routes["/download/"+asset] = archive
+
+	require.Equal(t, toolPath, Install("thething"))
+	contents, err := os.ReadFile(toolPath)
+	require.NoError(t, err)
+	require.Equal(t, "v1.2.3 binary", string(contents))
+	require.Equal(t, "v1.2.3", installedVersions()["thething"])
+
+	// already installed at the requested version, nothing is fetched
+	clear(routes)
+	installTool("thething", toolPath)
+}
+
+func Test_installTool_githubRelease_latest(t *testing.T) {
+	testToolDir(t)
+	require.SetAndRestore(t, &defaultSpecs, map[string]toolSpec{
+		"thething": {Version: "latest", Method: "github-release", With: map[string]any{"repo": "owner/thething"}},
+	})
+	require.SetAndRestore(t, &binnyManaged, map[string]toolSpec{})
+
+	toolPath := ToolPath("thething")
+	routes := map[string]any{}
+	serverURL := require.Server(t, routes)
+	require.SetAndRestore(t, &githubAPI, serverURL)
+
+	// the release is the executable itself
+	asset := fmt.Sprintf("thething-%s-%s", runtime.GOOS, runtime.GOARCH)
+	if config.Windows {
+		asset += ".exe"
+	}
+	release := map[string]any{
+		"tag_name": "v2.0.0",
+		"assets": []map[string]any{
+			{"name": asset, "browser_download_url": serverURL + "/download/" + asset},
+		},
+	}
+	routes["/repos/owner/thething/releases/latest"] = release
+	routes["/repos/owner/thething/releases/tags/v2.0.0"] = release
+	routes["/download/"+asset] = "\x7fELF v2.0.0 binary"
+
+	installTool("thething", toolPath)
+	contents, err := os.ReadFile(toolPath)
+	require.NoError(t, err)
+	require.Equal(t, "\x7fELF v2.0.0 binary", string(contents))
+	require.Equal(t, "v2.0.0", installedVersions()["thething"])
+}
+
+func Test_installTool_goInstallLocal(t *testing.T) {
+	testToolDir(t)
+
+	moduleDir := t.TempDir()
+	writeTestFile(t, filepath.Join(moduleDir, "go.mod"), "module example.com/thething\n\ngo 1.21\n")
+	writeTestFile(t, filepath.Join(moduleDir, "cmd", "thething", "main.go"),
+		"package main\n\nvar version string\n\nfunc main() { println(\"thething\", version) }\n")
+
+	require.SetAndRestore(t, &binnyManaged, map[string]toolSpec{
+		"thething": {
+			Version:     "current",
+			LocalModule: moduleDir,
+			Entrypoint:  "cmd/thething",
+			Method:      "go-install",
+			With:        map[string]any{"ldflags": []any{"-X main.version={{ .Version }}"}},
+		},
+	})
+
+	toolPath := Install("thething")
+	out, err := exec.Command(toolPath).CombinedOutput()
+	require.NoError(t, err)
+	require.Equal(t, "thething current", strings.TrimSpace(string(out)))
+}
+
+func Test_installTool_hostedShell(t *testing.T) {
+	if config.Windows {
+		t.Skip("hosted-shell install scripts are not supported on Windows")
+	}
+	testToolDir(t)
+
+	serverURL := require.Server(t, map[string]any{
+		"/install.sh": `#!/bin/sh
+# usage: install.sh -b <dir> <version>
+mkdir -p "$2/nested"
+printf '#!/bin/sh\necho %s\n' "$3" > "$2/nested/thething"
+`,
+	})
+	require.SetAndRestore(t, &binnyManaged, map[string]toolSpec{
+		"thething": {
+			Version: "v1.2.3",
+			Method:  "hosted-shell",
+			With:    map[string]any{"url": serverURL + "/install.sh", "args": "-b {{ .Destination }} {{ .Version }}"},
+		},
+	})
+
+	toolPath := Install("thething")
+	out, err := exec.Command(toolPath).CombinedOutput()
+	require.NoError(t, err)
+	require.Equal(t, "v1.2.3", strings.TrimSpace(string(out)))
+}
+
+func Test_installTool_unsupported(t *testing.T) {
+	testToolDir(t)
+	require.SetAndRestore(t, &binnyManaged, map[string]toolSpec{
+		"thething": {Version: "v1.2.3", Method: "unknown-method"},
+	})
+
+	err := lang.Catch(func() {
+		installTool("thething", ToolPath("thething"))
+	})
+	require.True(t, errors.Is(err, errUnsupported))
+}
+
+func Test_latestModuleVersion(t *testing.T) {
+	serverURL := require.Server(t, map[string]any{
+		"/github.com/!some!org/tool/@latest": map[string]any{"Version": "v1.4.0"},
+	})
+	require.SetAndRestore(t, &goProxy, serverURL)
+
+	require.Equal(t, "v1.4.0", latestModuleVersion("github.com/SomeOrg/tool"))
+	require.Equal(t, "v1.4.0", latestVersion(toolSpec{
+		Method:        "github-release",
+		VersionMethod: "go-proxy",
+		VersionWith:   map[string]any{"module": "github.com/SomeOrg/tool"},
+	}))
+}
+
+func Test_goProxyURL(t *testing.T) {
+	require.Equal(t, "https://proxy.golang.org", goProxyURL(""))
+	require.Equal(t, "https://proxy.golang.org", goProxyURL("direct"))
+	require.Equal(t, "https://goproxy.example.com", goProxyURL("https://goproxy.example.com/,direct"))
+	require.Equal(t, "http://localhost:3000", goProxyURL("off|http://localhost:3000|direct"))
+}
+
+func Test_selectAsset(t *testing.T) {
+	golangciLint := []string{
+		"golangci-lint-2.11.4-checksums.txt",
+		"golangci-lint-2.11.4-darwin-amd64.tar.gz",
+		"golangci-lint-2.11.4-darwin-arm64.tar.gz",
+		"golangci-lint-2.11.4-linux-386.tar.gz",
+		"golangci-lint-2.11.4-linux-amd64.deb",
+		"golangci-lint-2.11.4-linux-amd64.tar.gz",
+		"golangci-lint-2.11.4-linux-arm64.tar.gz",
+		"golangci-lint-2.11.4-linux-armv7.tar.gz",
+		"golangci-lint-2.11.4-windows-amd64.zip",
+	}
+	cosign := []string{
+		"cosign-linux-amd64.sig",
+		"cosign-linux-pivkey-pkcs11key-amd64",
+		"cosign-linux-amd64",
+		"cosign-linux-amd64.pem",
+		"cosign_3.0.5_amd64.deb",
+		"cosign-windows-amd64.exe",
+	}
+	gh := []string{
+		"gh_2.91.0_checksums.txt",
+		"gh_2.91.0_linux_amd64.tar.gz",
+		"gh_2.91.0_macOS_amd64.zip",
+		"gh_2.91.0_macOS_universal.pkg",
+		"gh_2.91.0_windows_amd64.msi",
+		"gh_2.91.0_windows_amd64.zip",
+	}
+	universal := []string{
+		"thething_Darwin_all.tar.gz",
+		"thething_Linux_i386.tar.gz",
+		"thething_Linux_x86_64.tar.gz",
+	}
+
+	tests := []struct {
+		tool     string
+		names    []string
+		platform string
+		expected string
+	}{
+		{tool: "golangci-lint", names: golangciLint, platform: "linux/amd64", expected: "golangci-lint-2.11.4-linux-amd64.tar.gz"},
+		{tool: "golangci-lint", names: golangciLint, platform: "linux/386", expected: "golangci-lint-2.11.4-linux-386.tar.gz"},
+		{tool: "golangci-lint", names: golangciLint, platform: "linux/arm", expected: "golangci-lint-2.11.4-linux-armv7.tar.gz"},
+		{tool: "golangci-lint", names: golangciLint, platform: "darwin/arm64", expected: "golangci-lint-2.11.4-darwin-arm64.tar.gz"},
+		{tool: "golangci-lint", names: golangciLint, platform: "windows/amd64", expected: "golangci-lint-2.11.4-windows-amd64.zip"},
+		{tool: "golangci-lint", names: golangciLint, platform: "freebsd/amd64"},
+		{tool: "cosign", names: cosign, platform: "linux/amd64", expected: "cosign-linux-amd64"},
+		{tool: "cosign", names: cosign, platform: "windows/amd64", expected: "cosign-windows-amd64.exe"},
+		{tool: "gh", names: gh, platform: "darwin/amd64", expected: "gh_2.91.0_macOS_amd64.zip"},
+		{tool: "gh", names: gh, platform: "windows/amd64", expected: "gh_2.91.0_windows_amd64.zip"},
+		{tool: "thething", names: universal, platform: "darwin/arm64", expected: "thething_Darwin_all.tar.gz"},
+		{tool: "thething", names: universal, platform: "linux/amd64", expected: "thething_Linux_x86_64.tar.gz"},
+		{tool: "thething", names: universal, platform: "linux/386", expected: "thething_Linux_i386.tar.gz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tool+" "+tt.platform, func(t *testing.T) {
+			goos, goarch, _ := strings.Cut(tt.platform, "/")
+			got := ""
+			if i := selectAsset(tt.tool, tt.names, goos, goarch); i >= 0 {
+				got = tt.names[i]
+			}
+			require.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func Test_recordInstalled(t *testing.T) {
+	toolDir := t.TempDir()
+	require.SetAndRestore(t, &config.ToolDir, toolDir)
+	state := `{"entries":[{"name":"thething","version":"v1.0.0"},{"name":"other","version":"v3.0.0","digests":{"sha256":"abc"}}]}`
+	writeTestFile(t, filepath.Join(toolDir, stateFile), state)
+
+	recordInstalled("thething", "v1.2.3")
+	recordInstalled("new", "v0.1.0")
+
+	require.Equal(t, map[string]string{"thething": "v1.2.3", "other": "v3.0.0", "new": "v0.1.0"}, installedVersions())
+	// fields binny records are kept
+	contents, err := os.ReadFile(filepath.Join(toolDir, stateFile))
+	require.NoError(t, err)
+	require.Contains(t, string(contents), `"sha256": "abc"`)
+}
+
+// testToolDir installs tools and writes the lock file to a temporary directory
+func Test_githubHeaders_redactsToken(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "ghp_installTestToken123")
+	githubHeaders()
+	require.Equal(t, redact.Mask, redact.Registered("ghp_installTestToken123"))
+}
+
+func testToolDir(t *testing.T) string {
+	dir := t.TempDir()
+	require.SetAndRestore(t, &config.ToolDir, dir)
+	require.SetAndRestore(t, &config.ToolLock, filepath.Join(dir, ".binny.lock"))
+	return dir
+}
+
+func writeTestFile(t *testing.T, path, contents string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+}
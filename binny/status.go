@@ -0,0 +1,96 @@
+package binny
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/file"
+	"github.com/anchore/go-make/run"
+	"github.com/anchore/go-make/template"
+)
+
+// stateFile is where binny records the tools it has installed, in the tool directory
+const stateFile = ".binny.state.json"
+
+// ToolStatus describes the installation state of a managed tool
+type ToolStatus struct {
+	// Name is the tool name, as configured in .binny.yaml
+	Name string
+	// Requested is the configured version, from the local .binny.yaml or go-make's defaults
+	Requested string
+	// Installed is the installed version, empty when not installed or unknown
+	Installed string
+	// Path is where the tool is installed
+	Path string
+	// Exists indicates a file exists at Path
+	Exists bool
+	// Local indicates the tool runs from a local source checkout, which has no version
+	Local bool
+}
+
+// UpToDate indicates the tool is installed and satisfies the requested version
+func (s ToolStatus) UpToDate() bool {
+	if s.Local {
+		return s.Exists
+	}
+	return s.Exists && matchesVersion(s.Requested, s.Installed)
+}
+
+// Tools returns the names of all tools configured in the project's .binny.yaml, sorted
+func Tools() []string {
+	return slices.Sorted(maps.Keys(binnyManaged))
+}
+
+// Status returns the installation state of the named tool, without installing or updating it
+func Status(name string) ToolStatus {
+	requested := findVersion(name)
+	if name == CMD {
+		// binny has a fallback version, reported also when it is not installed
+		requested = findBinnyVersion()
+	}
+	status := ToolStatus{
+		Name:      name,
+		Requested: requested,
+		Path:      ToolPath(name),
+		Local:     isLocalSpec(binnyManaged[name]),
+	}
+	status.Exists = file.Exists(status.Path)
+	if !status.Exists {
+		return status
+	}
+	if name == CMD {
+		// binny doesn't record itself in the state file
+		out, _ := run.Command(status.Path, run.Args("--version"), run.Quiet(), run.NoFail())
+		status.Installed = strings.TrimSpace(strings.TrimPrefix(out, CMD))
+		return status
+	}
+	status.Installed = installedVersions()[name]
+	return status
+}
+
+// installedVersions returns the tool versions recorded in binny's state file
+func installedVersions() map[string]string {
+	out := map[string]string{}
+	contents, err := os.ReadFile(filepath.Join(template.Render(config.ToolDir), stateFile))
+	if err != nil {
+		return out
+	}
+	var state struct {
+		Entries []struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"entries"`
+	}
+	if json.Unmarshal(contents, &state) != nil {
+		return out
+	}
+	for _, entry := range state.Entries {
+		out[entry.Name] = entry.Version
+	}
+	return out
+}
@@ -0,0 +1,47 @@
+package binny
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/require"
+)
+
+func Test_Status(t *testing.T) {
+	toolDir := t.TempDir()
+	require.SetAndRestore(t, &config.ToolDir, toolDir)
+	require.SetAndRestore(t, &binnyManaged, map[string]toolSpec{
+		"current":  {Version: "v1.2.0"},
+		"outdated": {Version: "v2.0.0"},
+		"missing":  {Version: "v3.0.0"},
+	})
+
+	for _, name := range []string{"current", "outdated"} {
+		require.NoError(t, os.WriteFile(ToolPath(name), []byte("#!/bin/sh\n"), 0o600))
+	}
+	state := `{"entries":[{"name":"current","version":"v1.2.0"},{"name":"outdated","version":"v1.9.0"}]}`
+	require.NoError(t, os.WriteFile(filepath.Join(toolDir, stateFile), []byte(state), 0o600))
+
+	require.Equal(t, []string{"current", "missing", "outdated"}, Tools())
+
+	current := Status("current")
+	require.Equal(t, ToolStatus{Name: "current", Requested: "v1.2.0", Installed: "v1.2.0", Path: ToolPath("current"), Exists: true}, current)
+	require.True(t, current.UpToDate())
+
+	outdated := Status("outdated")
+	require.Equal(t, "v1.9.0", outdated.Installed)
+	require.False(t, outdated.UpToDate())
+
+	missing := Status("missing")
+	require.False(t, missing.Exists)
+	require.False(t, missing.UpToDate())
+
+	// binny falls back to a default version, reported when it is not installed
+	require.SetAndRestore(t, &defaultSpecs, map[string]toolSpec{})
+	binny := Status(CMD)
+	require.False(t, binny.Exists)
+	require.NotEmpty(t, binny.Requested)
+	require.Equal(t, findBinnyVersion(), binny.Requested)
+}
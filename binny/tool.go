@@ -4,13 +4,14 @@ import (
 	"path/filepath"
 
 	"github.com/anchore/go-make/config"
-	"github.com/anchore/go-make/lang"
-	"github.com/anchore/go-make/run"
 	"github.com/anchore/go-make/template"
 )
 
+// InstallAll installs all tools configured in the project's .binny.yaml
 func InstallAll() {
-	lang.Return(run.Command(ManagedToolPath(CMD), run.Args("install", "-v")))
+	for _, name := range Tools() {
+		Install(name)
+	}
 }
 
 func ToolPath(toolName string) string {
@@ -0,0 +1,187 @@
+// Command init sets up go-make in a repository: the .make build script and its go.mod, the
+// Makefile forwarding to it and a starter .binny.yaml. Run it in the repository root:
+//
+//	go run github.com/anchore/go-make/cmd/init@latest
+//
+// The build script uses goreleaser.Tasks() when there is a .goreleaser.yaml, release.Tasks()
+// otherwise, and gotask.Tasks() when there is a Taskfile.yaml. Existing files are kept, unless
+// run with -overwrite, and -diff shows the changes without writing anything.
+package main
+
+import (
+	"embed"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+
+	. "github.com/anchore/go-make"
+	"github.com/anchore/go-make/color"
+	"github.com/anchore/go-make/file"
+	"github.com/anchore/go-make/lang"
+	"github.com/anchore/go-make/log"
+	"github.com/anchore/go-make/run"
+	"github.com/anchore/go-make/template"
+)
+
+const goMakeModule = "github.com/anchore/go-make"
+
+// forwardRule runs targets the Makefile doesn't define as go-make tasks
+const forwardRule = `
+.DEFAULT:
+%:
+	@go run -C .make . $@
+`
+
+//go:embed all:files
+var files embed.FS
+
+func main() {
+	defer lang.HandleErrors()
+
+	overwrite := flag.Bool("overwrite", false, "replace existing files")
+	diff := flag.Bool("diff", false, "show the changes to existing files without writing anything")
+	flag.Parse()
+
+	mode := template.SkipExisting
+	switch {
+	case *diff:
+		mode = template.Diff
+	case *overwrite:
+		mode = template.Overwrite
+	}
+	initRepo(".", mode, os.Stdout)
+}
+
+// project is what init detected about an existing repository
+type project struct {
+	// Goreleaser is set with a .goreleaser.yaml, using goreleaser.Tasks() rather than release.Tasks()
+	Goreleaser bool
+
+	// Taskfile is set with a Taskfile.yaml, exposing its tasks with gotask.Tasks()
+	Taskfile bool
+}
+
+func detect(dir string) project {
+	return project{
+		Goreleaser: file.Exists(filepath.Join(dir, ".goreleaser.yaml")),
+		Taskfile:   file.Exists(filepath.Join(dir, "Taskfile.yaml")),
+	}
+}
+
+// initRepo writes the go-make files to dir, writing the differences to existing files to w with
+// the Diff mode
+func initRepo(dir string, mode template.WriteMode, w io.Writer) {
+	p := detect(dir)
+	if p.Goreleaser {
+		log.Info("found .goreleaser.yaml, releasing with goreleaser.Tasks()")
+	}
+	if p.Taskfile {
+		log.Info("found Taskfile.yaml, including its tasks with gotask.Tasks()")
+	}
+
+	data := map[string]any{
+		"Goreleaser":  p.Goreleaser,
+		"Taskfile":    p.Taskfile,
+		"BinnyConfig": starterBinnyConfig(),
+	}
+	skipped, makefileSkipped := false, false
+	for _, f := range template.RenderDir("files", dir, data, template.FS(files), template.Existing(mode)) {
+		logFile(mode, f.Status, f.Path)
+		if f.Status == template.Skipped {
+			skipped = true
+			makefileSkipped = makefileSkipped || filepath.Base(f.Path) == "Makefile"
+		}
+		if f.Diff != "" {
+			_, _ = io.WriteString(w, f.Diff)
+		}
+	}
+
+	goMod := filepath.Join(dir, ".make", "go.mod")
+	if !file.Exists(goMod) {
+		if mode != template.Diff {
+			initModule(dir)
+		}
+		logFile(mode, template.Created, goMod)
+	}
+
+	if skipped {
+		log.Info(color.Grey("existing files were kept, run with -diff to see the changes or -overwrite to replace them"))
+	}
+	if makefileSkipped {
+		log.Info(color.Grey("to run go-make tasks with make, add this rule to the existing Makefile:\n%s"), forwardRule)
+	}
+}
+
+func logFile(mode template.WriteMode, status template.FileStatus, path string) {
+	text := string(status)
+	if mode == template.Diff && status != template.Unchanged {
+		text = "would be " + text
+	}
+	switch status {
+	case template.Created, template.Updated:
+		text = color.Green(text)
+	case template.Skipped:
+		text = color.Yellow(text)
+	default:
+		text = color.Grey(text)
+	}
+	log.Info("%s %s", text, path)
+}
+
+// starterBinnyConfig returns go-make's default tool versions, without the leading comments about
+// go-make's own .binny.yaml
+func starterBinnyConfig() string {
+	lines := strings.Split(DefaultBinnyConfig(), "\n")
+	for len(lines) > 0 {
+		line := strings.TrimSpace(lines[0])
+		if line != "" && !strings.HasPrefix(line, "#") {
+			break
+		}
+		lines = lines[1:]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// initModule creates the go.mod of the build script, requiring the go-make version this command
+// was run with
+func initModule(dir string) {
+	makeDir := filepath.Join(dir, ".make")
+	Run("go mod init", run.Args(makeModulePath(dir)), run.InDir(makeDir))
+	Run("go get", run.Args(goMakeModule+"@"+goMakeVersion()), run.InDir(makeDir))
+	Run("go mod tidy", run.InDir(makeDir))
+}
+
+// makeModulePath returns the module path of the build script, in the repository's module
+func makeModulePath(dir string) string {
+	contents, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return "make"
+	}
+	modPath := modfile.ModulePath(contents)
+	if modPath == "" {
+		return "make"
+	}
+	return fmt.Sprintf("%s/.make", modPath)
+}
+
+// goMakeVersion returns the go-make version this command was run with, as with
+// `go run github.com/anchore/go-make/cmd/init@v1.2.3`, or latest when built from source
+func goMakeVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Path != goMakeModule {
+		return "latest"
+	}
+	version := info.Main.Version
+	if !semver.IsValid(version) || semver.Build(version) != "" {
+		// local builds have no version, or a +dirty suffix
+		return "latest"
+	}
+	return version
+}
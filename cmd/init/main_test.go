@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anchore/go-make/file"
+	"github.com/anchore/go-make/require"
+	"github.com/anchore/go-make/template"
+)
+
+func Test_initRepo(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing map[string]string
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "library",
+			contains: []string{"release.Tasks()", "golint.Tasks()", "gotest.Tasks()"},
+			excludes: []string{"goreleaser", "gotask"},
+		},
+		{
+			name: "goreleaser and Taskfile",
+			existing: map[string]string{
+				".goreleaser.yaml": "version: 2\n",
+				"Taskfile.yaml":    "version: '3'\n",
+			},
+			contains: []string{"goreleaser.Tasks()", "gotask.Tasks()"},
+			excludes: []string{"release.Tasks()"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, contents := range tt.existing {
+				writeFile(t, filepath.Join(dir, name), contents)
+			}
+			// the go.mod is created with go get, which needs the network
+			writeFile(t, filepath.Join(dir, ".make", "go.mod"), "module make\n")
+
+			initRepo(dir, template.SkipExisting, &bytes.Buffer{})
+
+			mainGo := readFile(t, filepath.Join(dir, ".make", "main.go"))
+			formatted, err := format.Source([]byte(mainGo))
+			require.NoError(t, err)
+			require.Equal(t, string(formatted), mainGo)
+			for _, value := range tt.contains {
+				require.Contains(t, mainGo, value)
+			}
+			for _, value := range tt.excludes {
+				require.False(t, strings.Contains(mainGo, value))
+			}
+
+			require.Contains(t, readFile(t, filepath.Join(dir, "Makefile")), "@go run -C .make . $@")
+			require.Contains(t, readFile(t, filepath.Join(dir, "Makefile")), ".DEFAULT_GOAL := help")
+
+			binny := readFile(t, filepath.Join(dir, ".binny.yaml"))
+			require.Contains(t, binny, "name: binny")
+			// go-make's own header is replaced
+			require.False(t, strings.Contains(binny, "IMPORTANT"))
+		})
+	}
+}
+
+func Test_starterBinnyConfig(t *testing.T) {
+	config := starterBinnyConfig()
+	require.False(t, strings.HasPrefix(config, "#"))
+	require.Contains(t, config, "tools:")
+	// comments within the tools are kept
+	require.Contains(t, config, "  # ")
+}
+
+func Test_initRepo_existing(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "Makefile"), "build:\n\tgo build ./...\n")
+	writeFile(t, filepath.Join(dir, ".make", "go.mod"), "module make\n")
+
+	diff := &bytes.Buffer{}
+	initRepo(dir, template.Diff, diff)
+	require.Contains(t, diff.String(), "-\tgo build ./...")
+	require.False(t, file.Exists(filepath.Join(dir, ".make", "main.go")))
+
+	initRepo(dir, template.SkipExisting, &bytes.Buffer{})
+	require.Equal(t, "build:\n\tgo build ./...\n", readFile(t, filepath.Join(dir, "Makefile")))
+	require.True(t, file.Exists(filepath.Join(dir, ".make", "main.go")))
+
+	initRepo(dir, template.Overwrite, &bytes.Buffer{})
+	require.Contains(t, readFile(t, filepath.Join(dir, "Makefile")), "@go run -C .make . $@")
+}
+
+func Test_makeModulePath(t *testing.T) {
+	dir := t.TempDir()
+	require.Equal(t, "make", makeModulePath(dir))
+
+	writeFile(t, filepath.Join(dir, "go.mod"), "module github.com/example/project\n\ngo 1.25\n")
+	require.Equal(t, "github.com/example/project/.make", makeModulePath(dir))
+}
+
+func writeFile(t *testing.T, path, contents string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(contents)
+}
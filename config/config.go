@@ -40,6 +40,48 @@ var (
 	// Set via CI=true environment variable (automatically set by most CI systems).
 	CI = false
 
+	// GitHubActions indicates running in a GitHub Actions runner, which interprets workflow
+	// commands (e.g. ::error:: annotations) written to the log.
+	// Set via GITHUB_ACTIONS=true (automatically set by the runner).
+	GitHubActions = false
+
+	// NoDeps runs only the named tasks, skipping their dependencies and the tasks hooked to
+	// them via RunsOn, e.g. when a caller has already run them.
+	// Set via GOMAKE_NO_DEPS=true.
+	NoDeps = false
+
+	// AssumeYes answers prompts without asking: confirmations are accepted and other prompts
+	// use their defaults. Set via GOMAKE_ASSUME_YES=true or the --yes / -y command line flag.
+	AssumeYes = false
+
+	// AuditFile is a template string for the file every executed command is recorded to as a
+	// line of JSON, for provenance of the tools run by a build. Defaults to
+	// "{{ToolDir}}/audit.jsonl"; set "false" to disable. Set via GOMAKE_AUDIT.
+	AuditFile = "{{ToolDir}}/audit.jsonl"
+
+	// ToolLock is a template string for the lock file recording the version, download URL and
+	// SHA-256 checksum of each managed tool per platform, which installs are verified against.
+	// Defaults to "{{RootDir}}/.binny.lock"; set "false" to disable. Set via GOMAKE_TOOL_LOCK.
+	ToolLock = "{{RootDir}}/.binny.lock"
+
+	// EnvProfile selects additional .env files to load for commands, .env.<profile> and
+	// .env.<profile>.local, e.g. for per-environment settings. Set via GOMAKE_ENV.
+	EnvProfile = ""
+
+	// TemplateStrict fails rendering templates that reference missing keys, such as
+	// {{.Version}} when no Version is provided, rather than rendering "<no value>".
+	// Set via GOMAKE_TEMPLATE_STRICT=true.
+	TemplateStrict = false
+
+	// BinnyInstall installs managed tools with the binny executable, rather than go-make
+	// installing the github-release, go-install and hosted-shell methods itself.
+	// Set via GOMAKE_BINNY_INSTALL=true.
+	BinnyInstall = false
+
+	// ContainerEngine is the docker CLI compatible command used to run commands in containers,
+	// such as docker or podman. Set via GOMAKE_CONTAINER_ENGINE, defaults to docker.
+	ContainerEngine = "docker"
+
 	// Windows is true when running on Windows (runtime.GOOS == "windows").
 	Windows = runtime.GOOS == "windows"
 
@@ -52,6 +94,15 @@ func init() {
 	Trace, _ = strconv.ParseBool(Env("TRACE", "false"))
 	Debug, _ = strconv.ParseBool(Env("DEBUG", strconv.FormatBool(runnerDebug() || Trace)))
 	CI, _ = strconv.ParseBool(Env("CI", "false"))
+	GitHubActions, _ = strconv.ParseBool(Env("GITHUB_ACTIONS", "false"))
+	NoDeps, _ = strconv.ParseBool(Env("GOMAKE_NO_DEPS", "false"))
+	AssumeYes, _ = strconv.ParseBool(Env("GOMAKE_ASSUME_YES", "false"))
+	ContainerEngine = Env("GOMAKE_CONTAINER_ENGINE", ContainerEngine)
+	BinnyInstall, _ = strconv.ParseBool(Env("GOMAKE_BINNY_INSTALL", "false"))
+	TemplateStrict, _ = strconv.ParseBool(Env("GOMAKE_TEMPLATE_STRICT", "false"))
+	EnvProfile = Env("GOMAKE_ENV", "")
+	AuditFile = Env("GOMAKE_AUDIT", AuditFile)
+	ToolLock = Env("GOMAKE_TOOL_LOCK", ToolLock)
 	Cleanup = !Debug && !CI
 }
 
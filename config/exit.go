@@ -22,13 +22,18 @@ func OnExit(fn func()) {
 	onExit = append(onExit, fn)
 }
 
-// DoExit executes all registered exit handlers in reverse registration order.
-// This is automatically called by Makefile() via defer. Thread-safe.
+// DoExit executes all registered exit handlers in reverse registration order, each at most
+// once: handlers are removed as they run, so calling DoExit again only runs handlers registered
+// since. This is automatically called by Makefile() via defer, and before exiting on errors.
+// Thread-safe.
 func DoExit() {
 	onExitLock.Lock()
-	defer onExitLock.Unlock()
-	// reverse order, like defer
-	for i := len(onExit) - 1; i >= 0; i-- {
-		onExit[i]()
+	handlers := onExit
+	onExit = nil
+	onExitLock.Unlock()
+
+	// reverse order, like defer; handlers may register others, so the lock is not held
+	for i := len(handlers) - 1; i >= 0; i-- {
+		handlers[i]()
 	}
 }
@@ -25,6 +25,11 @@ func init() {
 	binny.DefaultConfig(lang.Return(defaultBinnyConfig.Open(".binny.yaml")))
 }
 
+// DefaultBinnyConfig returns the contents of go-make's embedded .binny.yaml, the default tool versions
+func DefaultBinnyConfig() string {
+	return string(lang.Return(defaultBinnyConfig.ReadFile(".binny.yaml")))
+}
+
 // RootDir returns the root directory of the repository; typically the repository root, located by the .git directory
 func RootDir() string {
 	return template.Render(config.RootDir)
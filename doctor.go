@@ -0,0 +1,269 @@
+package gomake
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/anchore/go-make/binny"
+	"github.com/anchore/go-make/color"
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/lang"
+	"github.com/anchore/go-make/run"
+	"github.com/anchore/go-make/template"
+)
+
+type checkStatus string
+
+const (
+	checkPass checkStatus = "pass"
+	checkWarn checkStatus = "warn"
+	checkFail checkStatus = "fail"
+)
+
+// checkResult is the outcome of a single doctor check
+type checkResult struct {
+	Name   string      `json:"name"`
+	Status checkStatus `json:"status"`
+	Detail string      `json:"detail"`
+	// Action describes how to fix a warning or failure
+	Action string `json:"action,omitempty"`
+}
+
+// Doctor checks everything go-make assumes about the local environment and prints a report,
+// failing when any check fails. Nothing is installed or modified.
+func (t *taskRunner) Doctor() {
+	results := doctorChecks()
+	if hasFlag("--json") {
+		writeDoctorJSON(os.Stdout, results)
+	} else {
+		writeDoctorReport(os.Stdout, results)
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Status == checkFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		panic(fmt.Errorf("doctor: %d check(s) failed", failed))
+	}
+}
+
+func doctorChecks() []checkResult {
+	out := []checkResult{
+		check("go", func() checkResult {
+			return checkCommand("go", checkFail, "install Go from https://go.dev/dl/", "version")
+		}),
+		check("git", func() checkResult {
+			return checkCommand("git", checkFail, "install git from https://git-scm.com/downloads", "--version")
+		}),
+		check("container engine", checkContainerEngine),
+		check("project root", checkRootDir),
+		check("tool directory", checkToolDir),
+		check("binny", checkBinny),
+	}
+	for _, tool := range binny.Tools() {
+		if tool == binny.CMD {
+			continue
+		}
+		out = append(out, check("tool: "+tool, func() checkResult { return checkTool(tool) }))
+	}
+	return append(out,
+		check(".env", checkDotEnv),
+		check("GitHub auth", checkGitHubAuth),
+	)
+}
+
+// check runs a single check, reporting a panic as a failure rather than aborting the report
+func check(name string, fn func() checkResult) (result checkResult) {
+	err := lang.Catch(func() {
+		result = fn()
+	})
+	if err != nil {
+		result = checkResult{Status: checkFail, Detail: err.Error()}
+	}
+	result.Name = name
+	return result
+}
+
+// checkCommand checks a command is on the PATH, reporting its version. missingStatus is the
+// status reported when it isn't found.
+func checkCommand(cmd string, missingStatus checkStatus, action string, versionArgs ...string) checkResult {
+	path, err := exec.LookPath(cmd)
+	if err != nil {
+		return checkResult{Status: missingStatus, Detail: cmd + " not found on PATH", Action: action}
+	}
+	version, err := run.Command(path, run.Args(versionArgs...), run.Quiet())
+	if err != nil {
+		return checkResult{Status: checkFail, Detail: fmt.Sprintf("%s is not working: %v", path, err), Action: action}
+	}
+	return checkResult{Status: checkPass, Detail: firstLine(version) + " (" + path + ")"}
+}
+
+func checkContainerEngine() checkResult {
+	engines := []string{config.ContainerEngine}
+	for _, engine := range []string{"docker", "podman"} {
+		if !slices.Contains(engines, engine) {
+			engines = append(engines, engine)
+		}
+	}
+	for _, engine := range engines {
+		if result := checkCommand(engine, checkWarn, "", "--version"); result.Status == checkPass {
+			return result
+		}
+	}
+	return checkResult{
+		Status: checkWarn,
+		Detail: fmt.Sprintf("no container engine found on PATH (GOMAKE_CONTAINER_ENGINE=%s), tasks using containers will fail", config.ContainerEngine),
+		Action: "install Docker (https://docs.docker.com/get-docker/) or Podman (https://podman.io/)",
+	}
+}
+
+func checkRootDir() checkResult {
+	var root string
+	if err := lang.Catch(func() { root = template.Render(config.RootDir) }); err != nil {
+		return checkResult{
+			Status: checkFail,
+			Detail: fmt.Sprintf("unable to resolve %s: %v", config.RootDir, err),
+			Action: "run from within a git repository, or set config.RootDir",
+		}
+	}
+	if stat, err := os.Stat(root); err != nil || !stat.IsDir() {
+		return checkResult{Status: checkFail, Detail: root + " is not a directory", Action: "check config.RootDir"}
+	}
+	return checkResult{Status: checkPass, Detail: root}
+}
+
+func checkToolDir() checkResult {
+	dir := template.Render(config.ToolDir)
+	action := "check the permissions of " + dir + ", or set config.ToolDir"
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return checkResult{Status: checkFail, Detail: fmt.Sprintf("unable to create %s: %v", dir, err), Action: action}
+	}
+	f, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return checkResult{Status: checkFail, Detail: fmt.Sprintf("%s is not writable: %v", dir, err), Action: action}
+	}
+	lang.Close(f, f.Name())
+	lang.Throw(os.Remove(f.Name()))
+	return checkResult{Status: checkPass, Detail: dir + " is writable"}
+}
+
+func checkBinny() checkResult {
+	status := binny.Status(binny.CMD)
+	switch {
+	case status.Local:
+		return checkResult{Status: checkPass, Detail: "built from local source (" + status.Path + ")"}
+	case !status.Exists && !config.BinnyInstall:
+		// go-make installs tools itself, binny is only installed for unsupported configurations
+		return checkResult{Status: checkPass, Detail: "not installed, only needed for tools go-make can't install"}
+	case !status.Exists:
+		return checkResult{
+			Status: checkWarn,
+			Detail: fmt.Sprintf("not installed, %s will be installed on first use", status.Requested),
+			Action: "run: make binny:install",
+		}
+	case !status.UpToDate():
+		return checkResult{
+			Status: checkWarn,
+			Detail: fmt.Sprintf("%s installed, %s requested; it will be updated on first use", lang.Default(status.Installed, "unknown version"), status.Requested),
+			Action: "run: make binny:install",
+		}
+	}
+	return checkResult{Status: checkPass, Detail: status.Installed + " (" + status.Path + ")"}
+}
+
+func checkTool(name string) checkResult {
+	status := binny.Status(name)
+	switch {
+	case status.Local:
+		return checkResult{Status: checkPass, Detail: "runs from local source"}
+	case !status.Exists:
+		return checkResult{
+			Status: checkWarn,
+			Detail: fmt.Sprintf("not installed, %s requested", status.Requested),
+			Action: "run: make binny:install",
+		}
+	case !status.UpToDate():
+		return checkResult{
+			Status: checkWarn,
+			Detail: fmt.Sprintf("%s installed, %s requested", lang.Default(status.Installed, "unknown version"), status.Requested),
+			Action: "run: make binny:install",
+		}
+	}
+	return checkResult{Status: checkPass, Detail: status.Installed}
+}
+
+func checkDotEnv() checkResult {
+	path, exists, ignored := run.DotEnvStatus()
+	switch {
+	case !exists:
+		return checkResult{Status: checkPass, Detail: "no .env file"}
+	case !ignored:
+		return checkResult{
+			Status: checkFail,
+			Detail: path + " is not gitignored, so it is not loaded",
+			Action: "add .env to .gitignore",
+		}
+	}
+	return checkResult{Status: checkPass, Detail: path + " is gitignored and loaded"}
+}
+
+func checkGitHubAuth() checkResult {
+	if os.Getenv("GITHUB_TOKEN") != "" {
+		return checkResult{Status: checkPass, Detail: "GITHUB_TOKEN is set"}
+	}
+	path, err := exec.LookPath("gh")
+	if status := binny.Status("gh"); status.Exists {
+		path, err = status.Path, nil
+	}
+	if err != nil {
+		return checkResult{
+			Status: checkWarn,
+			Detail: "gh not found on PATH and GITHUB_TOKEN not set, GitHub API calls are unauthenticated",
+			Action: "install the GitHub CLI (https://cli.github.com/) and run: gh auth login",
+		}
+	}
+	if _, err = run.Command(path, run.Args("auth", "status"), run.Quiet(), run.Stderr(io.Discard)); err != nil {
+		return checkResult{
+			Status: checkWarn,
+			Detail: "gh is not logged in, GitHub API calls are unauthenticated",
+			Action: "run: gh auth login",
+		}
+	}
+	return checkResult{Status: checkPass, Detail: "gh is logged in"}
+}
+
+func writeDoctorReport(w io.Writer, results []checkResult) {
+	sz := 0
+	for _, result := range results {
+		sz = max(sz, len(result.Name))
+	}
+	symbols := map[checkStatus]string{
+		checkPass: color.Green("✔"),
+		checkWarn: color.Yellow("!"),
+		checkFail: color.Red("✘"),
+	}
+	for _, result := range results {
+		lang.Return(fmt.Fprintf(w, "  %s %s% *s  %s\n", symbols[result.Status], result.Name, sz-len(result.Name), "", result.Detail))
+		if result.Action != "" && result.Status != checkPass {
+			lang.Return(fmt.Fprintf(w, "    % *s  %s\n", sz, "", color.Grey("→ "+result.Action)))
+		}
+	}
+}
+
+func writeDoctorJSON(w io.Writer, results []checkResult) {
+	out := lang.Return(json.MarshalIndent(results, "", "  "))
+	lang.Return(w.Write(append(out, '\n')))
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
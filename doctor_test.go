@@ -0,0 +1,93 @@
+package gomake
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/lang"
+	"github.com/anchore/go-make/require"
+)
+
+func Test_parseArgs(t *testing.T) {
+	tasks, values, flags := parseArgs([]string{"doctor", "--json", "test", "Name=a=b", "EMPTY=", "--suite=unit"})
+	require.Equal(t, []string{"doctor", "test"}, tasks)
+	require.Equal(t, map[string]string{"Name": "a=b", "EMPTY": ""}, values)
+	require.Equal(t, map[string]string{"--json": "", "--suite": "unit"}, flags)
+}
+
+func Test_checkFlags(t *testing.T) {
+	runner := func(flags ...string) *taskRunner {
+		_, _, parsed := parseArgs(flags)
+		return &taskRunner{
+			flags: parsed,
+			tasks: []*Task{{Name: "doctor", Flags: lang.List("--json")}, {Name: "test", Flags: lang.List("--suite")}},
+		}
+	}
+
+	// flags of any task and the global flags are accepted
+	require.NoError(t, lang.Catch(runner("--json", "--suite=unit", "-y").checkFlags))
+
+	err := lang.Catch(runner("--json", "--nope").checkFlags)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unknown flag")
+	require.Contains(t, err.Error(), "--nope")
+}
+
+func Test_check(t *testing.T) {
+	// panics are reported as failures
+	result := check("boom", func() checkResult {
+		panic(fmt.Errorf("something broke"))
+	})
+	require.Equal(t, checkResult{Name: "boom", Status: checkFail, Detail: "something broke"}, result)
+
+	result = check("ok", func() checkResult {
+		return checkResult{Status: checkPass, Detail: "fine"}
+	})
+	require.Equal(t, checkResult{Name: "ok", Status: checkPass, Detail: "fine"}, result)
+}
+
+func Test_checkCommand(t *testing.T) {
+	result := checkCommand("go", checkFail, "install go", "version")
+	require.Equal(t, checkPass, result.Status)
+	require.Contains(t, result.Detail, "go version")
+
+	result = checkCommand("some-missing-command", checkWarn, "install it", "--version")
+	require.Equal(t, checkResult{Status: checkWarn, Detail: "some-missing-command not found on PATH", Action: "install it"}, result)
+}
+
+func Test_checkToolDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".tool")
+	require.SetAndRestore(t, &config.ToolDir, dir)
+
+	result := checkToolDir()
+	require.Equal(t, checkPass, result.Status)
+
+	// a file in place of the directory
+	require.SetAndRestore(t, &config.ToolDir, filepath.Join("testdata", "failure-example", "main.go"))
+	result = checkToolDir()
+	require.Equal(t, checkFail, result.Status)
+	require.NotEmpty(t, result.Action)
+}
+
+func Test_doctorReport(t *testing.T) {
+	results := []checkResult{
+		{Name: "go", Status: checkPass, Detail: "go version go1.25"},
+		{Name: "binny", Status: checkWarn, Detail: "not installed", Action: "run: make binny:install"},
+	}
+
+	buf := bytes.Buffer{}
+	writeDoctorReport(&buf, results)
+	require.Contains(t, buf.String(), "go     go version go1.25\n")
+	require.Contains(t, buf.String(), "binny  not installed\n")
+	require.Contains(t, buf.String(), "→ run: make binny:install")
+
+	buf.Reset()
+	writeDoctorJSON(&buf, results)
+	var got []checkResult
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	require.Equal(t, results, got)
+}
@@ -0,0 +1,26 @@
+package gomake
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/anchore/go-make/redact"
+)
+
+// writeEnv writes the environment commands receive as sorted KEY=value lines, with secrets
+// masked. Values spanning lines are quoted, as they would be in a .env file.
+func writeEnv(w io.Writer, env []string) {
+	env = slices.Clone(env)
+	slices.Sort(env)
+	for _, entry := range env {
+		name, value, _ := strings.Cut(entry, "=")
+		value = redact.Secrets(redact.Value(name, value))
+		if strings.ContainsAny(value, "\n\r") {
+			value = strconv.Quote(value)
+		}
+		_, _ = fmt.Fprintf(w, "%s=%s\n", name, value)
+	}
+}
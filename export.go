@@ -0,0 +1,342 @@
+package gomake
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/anchore/go-make/file"
+	"github.com/anchore/go-make/lang"
+)
+
+// exportedTask is a task name as seen by other task runners: all tasks sharing the name, and
+// tasks hooked to it by RunsOn, merged into a single entry
+type exportedTask struct {
+	Name         string
+	Description  string
+	Aliases      []string
+	Dependencies []string // run before this task, in the order go-make runs them
+	Runs         bool     // false for labels with no behavior of their own
+}
+
+// exportedTasks returns all runnable task names and labels, sorted by name. Matrix instances
+// are not included, running their task runs all of them.
+func (t *taskRunner) exportedTasks() []exportedTask {
+	names := set[string]{}
+	for _, task := range t.tasks {
+		if task.values != nil {
+			continue
+		}
+		names.Add(task.Name)
+		names.Add(task.RunsOn...)
+	}
+	delete(names, "") // task groups
+
+	var out []exportedTask
+	for name := range names.Sorted() {
+		exported := exportedTask{Name: name}
+		deps := set[string]{}
+		addDep := func(dep string) {
+			if !deps.Contains(dep) {
+				deps.Add(dep)
+				exported.Dependencies = append(exported.Dependencies, dep)
+			}
+		}
+		// go-make runs tasks hooked by label before dependencies
+		for _, hooked := range t.findByLabel(name) {
+			addDep(hooked.Name)
+		}
+		for _, task := range t.tasks {
+			if task.Name != name {
+				continue
+			}
+			if task.Description != "" {
+				exported.Description = strings.Join(lang.Remove([]string{exported.Description, task.Description}, func(s string) bool { return s == "" }), "; ")
+			}
+			exported.Aliases = append(exported.Aliases, task.Aliases...)
+			exported.Runs = exported.Runs || task.Run != nil
+			for _, dep := range task.Dependencies {
+				addDep(dep)
+			}
+		}
+		out = append(out, exported)
+	}
+	return out
+}
+
+// buildCmdDir returns the directory of the build script, relative to the project root
+func (t *taskRunner) buildCmdDir() string {
+	return rootRelative(t.buildDir)
+}
+
+// rootRelative returns path relative to the project root, using forward slashes
+func rootRelative(path string) string {
+	rel := filepath.ToSlash(strings.TrimLeft(strings.TrimPrefix(path, RootDir()), `\/`))
+	if rel == "" {
+		return "."
+	}
+	return rel
+}
+
+// goRunArgs returns the arguments to `go` that run the named task from the project root
+func (t *taskRunner) goRunArgs(name string) []string {
+	return []string{"run", "-C", t.buildCmdDir(), ".", name}
+}
+
+// writeMakefile writes a GNU make Makefile with a PHONY target for every task and alias, each
+// running the task through go-make, which also resolves its dependencies
+func (t *taskRunner) writeMakefile(w io.Writer) {
+	for _, task := range t.tasks {
+		if task.values != nil || task.Name == "" {
+			// matrix instances are run through the catch-all, make would treat [...] as a pattern
+			continue
+		}
+		for _, name := range append([]string{task.Name}, task.Aliases...) {
+			if name == "new" {
+				writeMakefileNewRule(w, t.buildCmdDir())
+				continue
+			}
+			lang.Return(fmt.Fprintf(w, ".PHONY: %s\n", name))
+			lang.Return(fmt.Fprintf(w, "%s:\n", name))
+			lang.Return(fmt.Fprintf(w, "\t@go run -C %s . %s\n", t.buildCmdDir(), name))
+		}
+	}
+	// catch-all, could be the entire script except for FreeBSD
+	lang.Return(fmt.Fprintf(w, ".PHONY: *\n"))
+	lang.Return(fmt.Fprintf(w, ".DEFAULT:\n"))
+	lang.Return(fmt.Fprintf(w, "\t@go run -C %s . $@\n", t.buildCmdDir()))
+}
+
+// writeMakefileNewRule writes the rule for `make new <scaffold>`. make runs each goal separately,
+// so the goals following new are passed to it as arguments, and do nothing themselves.
+// KEY=VALUE arguments are make variables, which make passes in MAKEFLAGS, see makeVariables.
+func writeMakefileNewRule(w io.Writer, buildCmdDir string) {
+	lang.Return(fmt.Fprintf(w, ".PHONY: new\nnew:\n\t@go run -C %s . new $(SCAFFOLD_ARGS)\n", buildCmdDir))
+	lang.Return(fmt.Fprint(w, "ifeq (new,$(firstword $(MAKECMDGOALS)))\n"+
+		"SCAFFOLD_ARGS := $(wordlist 2,$(words $(MAKECMDGOALS)),$(MAKECMDGOALS))\n"+
+		"ifneq (,$(SCAFFOLD_ARGS))\n"+
+		"$(SCAFFOLD_ARGS):\n\t@:\n"+
+		"endif\n"+
+		"endif\n"))
+}
+
+var (
+	justInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+	justNameStart    = regexp.MustCompile(`^[a-zA-Z_]`)
+)
+
+// justName converts a task name to a valid just recipe name, e.g. lint:fix -> lint-fix
+func justName(name string) string {
+	name = justInvalidChars.ReplaceAllString(name, "-")
+	if !justNameStart.MatchString(name) {
+		name = "_" + name
+	}
+	return name
+}
+
+// writeJustfile writes a justfile with a recipe for every task, using just dependencies
+func (t *taskRunner) writeJustfile(w io.Writer) {
+	tasks := t.exportedTasks()
+	defined := set[string]{}
+	for _, task := range tasks {
+		defined.Add(justName(task.Name))
+	}
+
+	lang.Return(fmt.Fprintf(w, "# generated by go-make: go run -C %s . export:justfile\n\n", t.buildCmdDir()))
+	// just runs dependencies itself
+	lang.Return(fmt.Fprintf(w, "export GOMAKE_NO_DEPS := \"true\"\n\n"))
+	if !defined.Contains("default") {
+		// just runs the first recipe by default, go-make shows help
+		lang.Return(fmt.Fprintf(w, "default: help\n"))
+	}
+	for _, task := range tasks {
+		name := justName(task.Name)
+		lang.Return(fmt.Fprintf(w, "\n"))
+		if task.Description != "" {
+			lang.Return(fmt.Fprintf(w, "# %s\n", strings.ReplaceAll(task.Description, "\n", " ")))
+		}
+		lang.Return(fmt.Fprintf(w, "%s", name))
+		if len(task.Dependencies) > 0 {
+			lang.Return(fmt.Fprintf(w, ": %s\n", strings.Join(lang.Map(task.Dependencies, justName), " ")))
+		} else {
+			lang.Return(fmt.Fprintf(w, ":\n"))
+		}
+		if task.Runs {
+			lang.Return(fmt.Fprintf(w, "    @go %s\n", strings.Join(t.goRunArgs(task.Name), " ")))
+		}
+		for _, alias := range task.Aliases {
+			alias = justName(alias)
+			if defined.Contains(alias) {
+				continue
+			}
+			defined.Add(alias)
+			lang.Return(fmt.Fprintf(w, "\nalias %s := %s\n", alias, name))
+		}
+	}
+}
+
+// taskfileDocument is the subset of the Taskfile v3 schema written by writeTaskfile, see:
+// https://taskfile.dev/reference/schema
+type taskfileDocument struct {
+	Version string                  `yaml:"version"`
+	Env     map[string]string       `yaml:"env,omitempty"`
+	Tasks   map[string]taskfileTask `yaml:"tasks"`
+}
+
+type taskfileTask struct {
+	Desc    string   `yaml:"desc,omitempty"`
+	Aliases []string `yaml:"aliases,omitempty"`
+	Run     string   `yaml:"run,omitempty"`
+	Cmds    []any    `yaml:"cmds,omitempty"`
+}
+
+// writeTaskfile writes a Taskfile.yaml with a task for every task. Dependencies are invoked as
+// sequential task calls rather than Taskfile deps, which run in parallel, and each task runs
+// once per invocation as it does in go-make.
+func (t *taskRunner) writeTaskfile(w io.Writer) {
+	doc := taskfileDocument{
+		Version: "3",
+		// task runs dependencies itself
+		Env:   map[string]string{"GOMAKE_NO_DEPS": "true"},
+		Tasks: map[string]taskfileTask{},
+	}
+	for _, task := range t.exportedTasks() {
+		var cmds []any
+		for _, dep := range task.Dependencies {
+			cmds = append(cmds, map[string]string{"task": dep})
+		}
+		if task.Runs {
+			cmds = append(cmds, "go "+strings.Join(t.goRunArgs(task.Name), " "))
+		}
+		doc.Tasks[task.Name] = taskfileTask{
+			Desc:    task.Description,
+			Aliases: task.Aliases,
+			Run:     "once",
+			Cmds:    cmds,
+		}
+	}
+	lang.Return(fmt.Fprintf(w, "# generated by go-make: go run -C %s . export:taskfile\n", t.buildCmdDir()))
+	lang.Return(w.Write(lang.Return(yaml.Marshal(doc))))
+}
+
+// vscodeTasks is the subset of the VS Code tasks.json schema written by writeVSCodeTasks, see:
+// https://code.visualstudio.com/docs/editor/tasks-appendix
+type vscodeTasks struct {
+	Version string       `json:"version"`
+	Tasks   []vscodeTask `json:"tasks"`
+}
+
+type vscodeTask struct {
+	Label          string         `json:"label"`
+	Detail         string         `json:"detail,omitempty"`
+	Type           string         `json:"type"`
+	Command        string         `json:"command,omitempty"`
+	Args           []string       `json:"args,omitempty"`
+	Options        *vscodeOptions `json:"options,omitempty"`
+	DependsOn      []string       `json:"dependsOn,omitempty"`
+	DependsOrder   string         `json:"dependsOrder,omitempty"`
+	Group          string         `json:"group,omitempty"`
+	ProblemMatcher []string       `json:"problemMatcher"`
+}
+
+type vscodeOptions struct {
+	Cwd string            `json:"cwd,omitempty"`
+	Env map[string]string `json:"env,omitempty"`
+}
+
+// writeVSCodeTasks writes a .vscode/tasks.json with a task for every task, using VS Code
+// dependencies run in sequence
+func (t *taskRunner) writeVSCodeTasks(w io.Writer) {
+	doc := vscodeTasks{Version: "2.0.0", Tasks: []vscodeTask{}}
+	for _, task := range t.exportedTasks() {
+		vt := vscodeTask{
+			Label:          task.Name,
+			Detail:         task.Description,
+			Type:           "shell",
+			DependsOn:      task.Dependencies,
+			ProblemMatcher: []string{"$go"},
+		}
+		if task.Runs {
+			vt.Type = "process"
+			vt.Command = "go"
+			vt.Args = t.goRunArgs(task.Name)
+			vt.Options = &vscodeOptions{
+				Cwd: "${workspaceFolder}",
+				// VS Code runs dependencies itself
+				Env: map[string]string{"GOMAKE_NO_DEPS": "true"},
+			}
+		}
+		if len(task.Dependencies) > 1 {
+			vt.DependsOrder = "sequence"
+		}
+		switch task.Name {
+		case "build", "test":
+			vt.Group = task.Name
+		}
+		doc.Tasks = append(doc.Tasks, vt)
+	}
+	out := lang.Return(json.MarshalIndent(doc, "", "  "))
+	lang.Return(w.Write(append(out, '\n')))
+}
+
+// builtinTaskNames are the tasks added to every Makefile, which are not useful as CI jobs
+var builtinTaskNames = []string{"help", "clean", "dependencies:update"}
+
+type workflowDocument struct {
+	Name string                 `yaml:"name"`
+	On   map[string]any         `yaml:"on"`
+	Jobs map[string]workflowJob `yaml:"jobs"`
+}
+
+type workflowJob struct {
+	Name   string         `yaml:"name"`
+	RunsOn string         `yaml:"runs-on"`
+	Steps  []workflowStep `yaml:"steps"`
+}
+
+type workflowStep struct {
+	Name string            `yaml:"name,omitempty"`
+	Uses string            `yaml:"uses,omitempty"`
+	With map[string]string `yaml:"with,omitempty"`
+	Run  string            `yaml:"run,omitempty"`
+}
+
+// writeWorkflow writes a GitHub Actions workflow stub with a job for every described task,
+// as a starting point for a .github/workflows file. Jobs run on separate machines, so each
+// runs its task including dependencies.
+func (t *taskRunner) writeWorkflow(w io.Writer) {
+	doc := workflowDocument{
+		Name: "Validations",
+		On: map[string]any{
+			"push":         map[string]any{"branches": []string{"main"}},
+			"pull_request": nil,
+		},
+		Jobs: map[string]workflowJob{},
+	}
+	goModFile := "go.mod"
+	if found := file.FindParent(t.buildDir, "go.mod"); found != "" {
+		goModFile = rootRelative(found)
+	}
+	for _, task := range t.exportedTasks() {
+		if task.Description == "" || slices.Contains(builtinTaskNames, task.Name) {
+			continue
+		}
+		doc.Jobs[justName(task.Name)] = workflowJob{
+			Name:   task.Description,
+			RunsOn: "ubuntu-latest",
+			Steps: []workflowStep{
+				{Uses: "actions/checkout@v4"},
+				{Uses: "actions/setup-go@v5", With: map[string]string{"go-version-file": goModFile}},
+				{Name: task.Name, Run: "go " + strings.Join(t.goRunArgs(task.Name), " ")},
+			},
+		}
+	}
+	lang.Return(fmt.Fprintf(w, "# generated by go-make: go run -C %s . export:workflow\n", t.buildCmdDir()))
+	lang.Return(w.Write(lang.Return(yaml.Marshal(doc))))
+}
@@ -0,0 +1,178 @@
+package gomake
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/lang"
+	"github.com/anchore/go-make/require"
+)
+
+func exportTestRunner(t *testing.T) *taskRunner {
+	root := t.TempDir()
+	require.SetAndRestore(t, &config.RootDir, root)
+
+	r := &taskRunner{buildDir: filepath.Join(root, ".make")}
+	r.addTasks(
+		Task{
+			Name:         "build",
+			Description:  "build the app",
+			Dependencies: Deps("generate"),
+			Run:          func() {},
+		},
+		Task{
+			Name: "generate",
+			Run:  func() {},
+		},
+		Task{
+			Name:        "lint:fix",
+			Aliases:     lang.List("lint-fix", "fix"),
+			Description: "fix lint issues",
+			Run:         func() {},
+		},
+		Task{
+			Name:   "unit",
+			RunsOn: lang.List("test"),
+			Run:    func() {},
+		},
+		Task{
+			Name:        "test",
+			Description: "run all tests",
+		},
+		Task{
+			Name:   "cross",
+			Matrix: map[string][]string{"GOOS": {"linux", "darwin"}},
+			Run:    func() {},
+		},
+	)
+	return r
+}
+
+func Test_exportedTasks(t *testing.T) {
+	r := exportTestRunner(t)
+
+	byName := map[string]exportedTask{}
+	var names []string
+	for _, task := range r.exportedTasks() {
+		byName[task.Name] = task
+		names = append(names, task.Name)
+	}
+
+	// sorted, without matrix instances
+	require.Equal(t, []string{"build", "cross", "generate", "lint:fix", "test", "unit"}, names)
+
+	require.Equal(t, exportedTask{Name: "build", Description: "build the app", Dependencies: []string{"generate"}, Runs: true}, byName["build"])
+	require.Equal(t, exportedTask{Name: "lint:fix", Description: "fix lint issues", Aliases: []string{"lint-fix", "fix"}, Runs: true}, byName["lint:fix"])
+	// labels depend on the tasks hooked to them
+	require.Equal(t, exportedTask{Name: "test", Description: "run all tests", Dependencies: []string{"unit"}}, byName["test"])
+}
+
+func Test_writeMakefile(t *testing.T) {
+	buf := bytes.Buffer{}
+	exportTestRunner(t).writeMakefile(&buf)
+
+	require.Contains(t, buf.String(), ".PHONY: lint-fix\nlint-fix:\n\t@go run -C .make . lint-fix\n")
+	require.False(t, strings.Contains(buf.String(), "cross["))
+	require.Contains(t, buf.String(), ".DEFAULT:\n\t@go run -C .make . $@\n")
+}
+
+func Test_writeMakefile_new(t *testing.T) {
+	r := exportTestRunner(t)
+	r.tasks = append(r.tasks, r.newTask())
+	buf := bytes.Buffer{}
+	r.writeMakefile(&buf)
+
+	// `make new cmd` passes the scaffold name to the new task, rather than running a cmd task
+	require.Contains(t, buf.String(), "new:\n\t@go run -C .make . new $(SCAFFOLD_ARGS)\n")
+	require.Contains(t, buf.String(), "$(SCAFFOLD_ARGS):\n\t@:\n")
+}
+
+func Test_writeJustfile(t *testing.T) {
+	buf := bytes.Buffer{}
+	exportTestRunner(t).writeJustfile(&buf)
+	out := buf.String()
+
+	require.Contains(t, out, `export GOMAKE_NO_DEPS := "true"`)
+	require.Contains(t, out, "default: help\n")
+	require.Contains(t, out, "# build the app\nbuild: generate\n    @go run -C .make . build\n")
+	// names are converted to valid recipe names, aliases which collide are skipped
+	require.Contains(t, out, "# fix lint issues\nlint-fix:\n    @go run -C .make . lint:fix\n")
+	require.Contains(t, out, "alias fix := lint-fix\n")
+	require.False(t, strings.Contains(out, "alias lint-fix"))
+	// labels have no recipe body
+	require.Contains(t, out, "# run all tests\ntest: unit\n\n")
+}
+
+func Test_writeTaskfile(t *testing.T) {
+	buf := bytes.Buffer{}
+	exportTestRunner(t).writeTaskfile(&buf)
+
+	var doc taskfileDocument
+	require.NoError(t, yaml.Unmarshal(buf.Bytes(), &doc))
+	require.Equal(t, "3", doc.Version)
+	require.Equal(t, "true", doc.Env["GOMAKE_NO_DEPS"])
+
+	lintFix := doc.Tasks["lint:fix"]
+	require.Equal(t, "fix lint issues", lintFix.Desc)
+	require.Equal(t, []string{"lint-fix", "fix"}, lintFix.Aliases)
+	require.Equal(t, "once", lintFix.Run)
+
+	build := doc.Tasks["build"]
+	require.Equal(t, 2, len(build.Cmds))
+	require.Equal(t, map[string]any{"task": "generate"}, build.Cmds[0].(map[string]any))
+	require.Equal(t, "go run -C .make . build", build.Cmds[1].(string))
+
+	test := doc.Tasks["test"]
+	require.Equal(t, 1, len(test.Cmds))
+	require.Equal(t, map[string]any{"task": "unit"}, test.Cmds[0].(map[string]any))
+}
+
+func Test_writeVSCodeTasks(t *testing.T) {
+	buf := bytes.Buffer{}
+	exportTestRunner(t).writeVSCodeTasks(&buf)
+
+	var doc vscodeTasks
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	require.Equal(t, "2.0.0", doc.Version)
+
+	byLabel := map[string]vscodeTask{}
+	for _, task := range doc.Tasks {
+		byLabel[task.Label] = task
+	}
+	build := byLabel["build"]
+	require.Equal(t, "build the app", build.Detail)
+	require.Equal(t, "go", build.Command)
+	require.Equal(t, []string{"run", "-C", ".make", ".", "build"}, build.Args)
+	require.Equal(t, []string{"generate"}, build.DependsOn)
+	require.Equal(t, "true", build.Options.Env["GOMAKE_NO_DEPS"])
+	require.Equal(t, "build", build.Group)
+
+	test := byLabel["test"]
+	require.Equal(t, "", test.Command)
+	require.Equal(t, []string{"unit"}, test.DependsOn)
+	require.Equal(t, "test", test.Group)
+}
+
+func Test_writeWorkflow(t *testing.T) {
+	buf := bytes.Buffer{}
+	exportTestRunner(t).writeWorkflow(&buf)
+
+	var doc workflowDocument
+	require.NoError(t, yaml.Unmarshal(buf.Bytes(), &doc))
+
+	// only described tasks, excluding built-in labels other than test
+	require.Equal(t, 3, len(doc.Jobs))
+	job := doc.Jobs["lint-fix"]
+	require.Equal(t, "fix lint issues", job.Name)
+	require.Equal(t, "go run -C .make . lint:fix", job.Steps[len(job.Steps)-1].Run)
+	for _, name := range []string{"build", "test"} {
+		_, hasJob := doc.Jobs[name]
+		require.True(t, hasJob)
+	}
+}
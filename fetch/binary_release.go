@@ -5,12 +5,16 @@ import (
 	"archive/zip"
 	"bytes"
 	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
+	"strings"
 
 	"github.com/anchore/go-make/file"
 	"github.com/anchore/go-make/lang"
@@ -42,29 +46,63 @@ type ReleaseSpec struct {
 	//   - OS/Arch pair: "darwin/arm64", "linux/amd64"
 	// More specific keys take precedence over less specific ones.
 	Platform map[string]map[string]string
+
+	// SHA256 is the expected hex encoded SHA-256 checksum of the downloaded file. When set,
+	// downloads with a different checksum are refused with ErrChecksumMismatch.
+	SHA256 string
+}
+
+// ErrChecksumMismatch indicates a downloaded file doesn't have the expected checksum
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// Artifact is a file downloaded for a binary release
+type Artifact struct {
+	// URL is where the file was downloaded from
+	URL string
+
+	// SHA256 is the hex encoded SHA-256 checksum of the downloaded file
+	SHA256 string
 }
 
 // BinaryRelease downloads a binary archive from the URL defined in spec, extracts
-// the file matching toolPath's basename, and writes it to toolPath with executable
-// permissions (0500). Supports .zip and .tar.gz archives.
+// the file matching toolPath's basename, in any directory of the archive, and writes
+// it to toolPath with executable permissions (0500), replacing an existing file.
+// Supports .zip and .tar.gz archives, and ELF, Mach-O and PE executables downloaded as-is;
+// anything else is refused.
 func BinaryRelease(toolPath string, spec ReleaseSpec) error {
+	_, err := DownloadBinaryRelease(toolPath, spec)
+	return err
+}
+
+// DownloadBinaryRelease is BinaryRelease, returning the URL and checksum of the downloaded
+// file, such as to record in a lock file and verify later downloads with ReleaseSpec.SHA256.
+func DownloadBinaryRelease(toolPath string, spec ReleaseSpec) (Artifact, error) {
 	url := spec.render(runtime.GOOS, runtime.GOARCH)
 
 	buf := bytes.Buffer{}
 	_, err := Fetch(url, Writer(&buf))
 	if err != nil {
-		return err
+		return Artifact{}, err
+	}
+	digest := sha256.Sum256(buf.Bytes())
+	artifact := Artifact{URL: url, SHA256: hex.EncodeToString(digest[:])}
+	if spec.SHA256 != "" && !strings.EqualFold(spec.SHA256, artifact.SHA256) {
+		return artifact, fmt.Errorf("%w: %v has SHA-256 %v, expected %v", ErrChecksumMismatch, url, artifact.SHA256, spec.SHA256)
 	}
-	contents := buf.Bytes()
-	contents = getArchiveFileContents(contents, filepath.Base(toolPath))
+
+	contents := getArchiveFileContents(buf.Bytes(), filepath.Base(toolPath))
 	if contents == nil {
-		return fmt.Errorf("unable to read archive from: %v", url)
+		return artifact, fmt.Errorf("unable to read archive from: %v", url)
 	}
 	dir := filepath.Dir(toolPath)
 	if !file.Exists(dir) {
 		lang.Throw(os.MkdirAll(dir, 0o700|os.ModeDir))
 	}
-	return os.WriteFile(toolPath, contents, 0o500) //nolint:gosec // needs read + execute permissions
+	// a previous release is read-only
+	if err = os.Remove(toolPath); err != nil && !os.IsNotExist(err) {
+		return artifact, err
+	}
+	return artifact, os.WriteFile(toolPath, contents, 0o500) //nolint:gosec // needs read + execute permissions
 }
 
 func getArchiveFileContents(archive []byte, file string) []byte {
@@ -82,18 +120,59 @@ func getArchiveFileContents(archive []byte, file string) []byte {
 	}
 	errs = append(errs, err)
 
+	if isExecutable(archive) {
+		// the release is the executable itself
+		return archive
+	}
+
 	panic(fmt.Errorf("unable to read archive after attempting readers: %w", errors.Join(errs...)))
 }
 
+// executableHeaders are the magic numbers starting ELF, Mach-O and PE executables
+var executableHeaders = [][]byte{
+	[]byte("\x7fELF"),
+	{0xfe, 0xed, 0xfa, 0xce}, {0xfe, 0xed, 0xfa, 0xcf}, // Mach-O 32 and 64 bit, big endian
+	{0xce, 0xfa, 0xed, 0xfe}, {0xcf, 0xfa, 0xed, 0xfe}, // Mach-O 32 and 64 bit, little endian
+	{0xca, 0xfe, 0xba, 0xbe}, // Mach-O universal binary
+	[]byte("MZ"),             // PE
+}
+
+// isExecutable indicates the contents are an executable, rather than something else served in
+// place of a release, such as an HTML error page
+func isExecutable(contents []byte) bool {
+	for _, header := range executableHeaders {
+		if bytes.HasPrefix(contents, header) {
+			return true
+		}
+	}
+	return false
+}
+
+// archiveEntryMatches indicates the archive entry is the named file, at the top level or in a directory
+func archiveEntryMatches(entry, file string) bool {
+	return entry == file || path.Base(entry) == file
+}
+
 func getZipArchiveFileContents(archive []byte, file string) ([]byte, error) {
 	zipReader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
 	if err != nil {
 		return nil, err
 	}
-	f, err := zipReader.Open(file)
+	var entry *zip.File
+	for _, f := range zipReader.File {
+		if !f.FileInfo().IsDir() && archiveEntryMatches(f.Name, file) {
+			entry = f
+			break
+		}
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("file not found: %v", file)
+	}
+	f, err := entry.Open()
 	if err != nil {
 		return nil, err
 	}
+	defer lang.Close(f, file)
 	contents, err := io.ReadAll(f)
 	if err != nil {
 		return nil, err
@@ -110,7 +189,7 @@ func getTarGzArchiveFileContents(archive []byte, fileName string) ([]byte, error
 			if err != nil {
 				return nil, err
 			}
-			if hdr.Name == fileName {
+			if hdr.Typeflag == tar.TypeReg && archiveEntryMatches(hdr.Name, fileName) {
 				if hdr.Size > int64(MaxFileSize) {
 					return nil, fmt.Errorf("refusing to extract file %v larger than %s, declared size: %v", fileName, file.HumanizeBytes(MaxFileSize), file.HumanizeBytes(hdr.Size))
 				}
@@ -1,13 +1,19 @@
 package fetch
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/file"
+	"github.com/anchore/go-make/lang"
 	"github.com/anchore/go-make/require"
 )
 
@@ -56,6 +62,61 @@ func Test_BinaryRelease(t *testing.T) {
 	require.Equal(t, content, string(extractedContent))
 }
 
+func Test_BinaryRelease_layouts(t *testing.T) {
+	content := []byte("\x7fELF thething")
+	serverURL := require.Server(t, map[string]any{
+		"/nested.tar.gz": require.Gzip(require.Tar(map[string][]byte{
+			"thething_1.2.3/README.md": []byte("readme"),
+			"thething_1.2.3/thething":  content,
+		})),
+		"/nested.zip": require.Zip(map[string][]byte{
+			"thething_1.2.3/bin/thething": content,
+		}),
+		"/thething-linux-amd64":  content,
+		"/thething-darwin-arm64": []byte{0xcf, 0xfa, 0xed, 0xfe, 't', 'h', 'e'},
+		"/not-found":             "<html><body>Not Found</body></html>",
+	})
+
+	toolPath := filepath.Join(t.TempDir(), "thething")
+	for _, name := range []string{"nested.tar.gz", "nested.zip", "thething-linux-amd64"} {
+		t.Run(name, func(t *testing.T) {
+			// the executable from the previous install is replaced
+			require.NoError(t, BinaryRelease(toolPath, ReleaseSpec{URL: serverURL + "/" + name}))
+			contents, err := os.ReadFile(toolPath)
+			require.NoError(t, err)
+			require.Equal(t, string(content), string(contents))
+		})
+	}
+
+	require.NoError(t, BinaryRelease(toolPath, ReleaseSpec{URL: serverURL + "/thething-darwin-arm64"}))
+
+	// other content is not taken as the executable
+	err := lang.Catch(func() {
+		_ = BinaryRelease(toolPath, ReleaseSpec{URL: serverURL + "/not-found"})
+	})
+	require.Error(t, err)
+}
+
+func Test_DownloadBinaryRelease_checksum(t *testing.T) {
+	content := []byte("\x7fELF thething")
+	digest := sha256.Sum256(content)
+	checksum := hex.EncodeToString(digest[:])
+	serverURL := require.Server(t, map[string]any{
+		"/thething": content,
+	})
+	toolPath := filepath.Join(t.TempDir(), "thething")
+
+	artifact, err := DownloadBinaryRelease(toolPath, ReleaseSpec{URL: serverURL + "/thething", SHA256: checksum})
+	require.NoError(t, err)
+	require.Equal(t, Artifact{URL: serverURL + "/thething", SHA256: checksum}, artifact)
+
+	require.NoError(t, os.Remove(toolPath))
+	_, err = DownloadBinaryRelease(toolPath, ReleaseSpec{URL: serverURL + "/thething", SHA256: strings.Repeat("0", 64)})
+	require.True(t, errors.Is(err, ErrChecksumMismatch))
+	// nothing is written
+	require.False(t, file.Exists(toolPath))
+}
+
 func Test_ReleaseSpec_render(t *testing.T) {
 	tests := []struct {
 		expected string
@@ -6,9 +6,9 @@ import (
 	"net/http"
 	"net/url"
 
-	"github.com/anchore/go-make/internal/redact"
 	"github.com/anchore/go-make/lang"
 	"github.com/anchore/go-make/log"
+	"github.com/anchore/go-make/redact"
 )
 
 // Option is a functional option for customizing HTTP fetch behavior.
@@ -13,6 +13,7 @@ import (
 
 func init() {
 	template.Globals["GitRoot"] = Root
+	template.Globals["gitDescribe"] = Describe
 }
 
 // Root returns the root directory of the git repository by searching upward
@@ -30,6 +31,13 @@ func Revision() string {
 	return lang.Return(run.Command("git", run.Args("rev-parse", "--short", "HEAD")))
 }
 
+// Describe returns the most recent tag reachable from HEAD, followed by the number of commits
+// since and the abbreviated commit SHA when HEAD is not tagged, and -dirty with uncommitted
+// changes. Returns the abbreviated commit SHA when there are no tags.
+func Describe() string {
+	return lang.Return(run.Command("git", run.Args("describe", "--tags", "--always", "--dirty"), run.Quiet()))
+}
+
 // InClone performs a shallow clone of the repository at the specified ref into a
 // temporary directory, runs the provided function, then cleans up. Useful for
 // operations that need to work with a specific version of a repo without affecting
@@ -238,8 +238,8 @@ func pushTagWithToken(cfg PushTagConfig) {
 // the GitHub token. Using a dedicated, project-prefixed name (not the bare
 // caller-facing TAG_TOKEN) keeps the credential passing explicit and decoupled
 // from how callers source the token. The name deliberately avoids the bare
-// "GO_" prefix so it does not collide with run.skipEnvVar's GO* / CGO_*
-// filter on inherited environment.
+// "GO_" prefix so it does not collide with the GO* / CGO_* filter of
+// run.DefaultEnvPolicy on inherited environment.
 const tagTokenEnvVar = "ANCHORE_GO_MAKE_TAG_TOKEN" //nolint:gosec // env var name, not a credential
 
 // askpassScript is a POSIX shell script invoked by git when it needs HTTPS
@@ -23,6 +23,7 @@ import (
 	"github.com/anchore/go-make/git"
 	"github.com/anchore/go-make/lang"
 	"github.com/anchore/go-make/log"
+	"github.com/anchore/go-make/redact"
 )
 
 type Option func(Api)
@@ -73,6 +74,8 @@ func NewClient(params ...Param) Api {
 		}
 	}
 
+	redact.Register(a.Token)
+
 	return a
 }
 
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/bmatcuk/doublestar/v4"
 
@@ -108,6 +109,20 @@ Promise.all([artifact.uploadArtifact(archiveName, files, baseDir, opts).then(({
 	return strconv.ParseInt(id, 10, 64)
 }
 
+// UploadAuditLog uploads the audit log of executed commands, see run.AuditFile, as an artifact
+// attached to the currently running workflow, named "audit-log". Does nothing, returning 0, when
+// there is no audit log.
+func (a Api) UploadAuditLog() (int64, error) {
+	auditFile := run.AuditFile()
+	if auditFile == "" || !file.Exists(auditFile) {
+		return 0, nil
+	}
+	return a.UploadArtifactDir(filepath.Dir(auditFile), UploadArtifactOption{
+		ArtifactName: "audit-log" + MatrixSuffix,
+		Files:        []string{auditFile},
+	})
+}
+
 func listMatchingFiles(baseDir string, opts *UploadArtifactOption) []string {
 	var out []string
 	baseDir = lang.Return(filepath.Abs(baseDir))
@@ -138,14 +153,23 @@ func ensureActionsArtifactInstalled(path string) {
 	file.InDir(path, func() {
 		if !isActionsArtifactInstalled() {
 			if nil != lang.Catch(func() {
-				Run("npm install @actions/artifact@latest")
+				Run("npm install @actions/artifact@latest", npmInstallOptions())
 			}) {
-				Run("npm install @actions/artifact@" + knownActionsArtifactVersion)
+				Run("npm install @actions/artifact@"+knownActionsArtifactVersion, npmInstallOptions())
 			}
 		}
 	})
 }
 
+// npmInstallOptions retries npm installs failing with transient network errors, and limits how
+// long a stalled registry can block an upload
+func npmInstallOptions() run.Option {
+	return run.Options(
+		run.Retry(3, 2*time.Second, run.OnStderr(`ECONNRESET|ETIMEDOUT|EAI_AGAIN|ENOTFOUND|socket hang up|E5\d\d`)),
+		run.Timeout(5*time.Minute),
+	)
+}
+
 func isActionsArtifactInstalled() bool {
 	return strings.Contains(Run("npm list @actions/artifact", run.Quiet(), run.NoFail()), "@actions/artifact")
 }
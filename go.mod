@@ -7,4 +7,5 @@ require (
 	github.com/goccy/go-yaml v1.19.2
 	golang.org/x/mod v0.38.0
 	golang.org/x/sys v0.47.0
+	golang.org/x/term v0.45.0
 )
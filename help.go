@@ -15,7 +15,7 @@ func (t *taskRunner) Help() {
 	fmt.Print("Tasks:\n")
 	sz := 0
 	for _, t := range t.tasks {
-		if len(t.Name) > sz {
+		if len(t.Name) > sz && t.values == nil {
 			sz = len(t.Name)
 		}
 	}
@@ -47,6 +47,9 @@ func (t *taskRunner) Help() {
 			for _, label := range task.Dependencies {
 				deps.Add(label)
 			}
+			for _, instance := range task.instances {
+				deps.Add(instance.Name)
+			}
 		}
 
 		for _, task := range t.findByLabel(taskName) {
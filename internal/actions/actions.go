@@ -0,0 +1,95 @@
+// Package actions formats GitHub Actions workflow commands, such as the ::error:: annotations
+// that surface failures inline on a pull request diff, and finds the diagnostics worth
+// annotating in the output of well-known tools.
+package actions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Annotation is a single file-level message rendered as a workflow command, e.g.
+// ::error file=pkg/file.go,line=12,col=5::message
+type Annotation struct {
+	// Level is the workflow command: error, warning or notice
+	Level string
+	// File is the path relative to the repository root
+	File string
+	// Line is the 1-based line number, 0 to omit
+	Line int
+	// Col is the 1-based column number, 0 to omit
+	Col int
+	// Message is the annotation text, may contain multiple lines
+	Message string
+}
+
+// String renders the annotation as a workflow command
+func (a Annotation) String() string {
+	var props []string
+	if a.File != "" {
+		props = append(props, "file="+escapeProperty(filepath.ToSlash(a.File)))
+	}
+	if a.Line > 0 {
+		props = append(props, fmt.Sprintf("line=%d", a.Line))
+	}
+	if a.Col > 0 {
+		props = append(props, fmt.Sprintf("col=%d", a.Col))
+	}
+	level := a.Level
+	if level == "" {
+		level = "error"
+	}
+	if len(props) > 0 {
+		level += " " + strings.Join(props, ",")
+	}
+	return "::" + level + "::" + escapeData(StripANSI(a.Message))
+}
+
+// Workspace returns the directory annotation paths are relative to: GITHUB_WORKSPACE when
+// set by the runner, otherwise the current working directory
+func Workspace() string {
+	if ws := os.Getenv("GITHUB_WORKSPACE"); ws != "" {
+		return ws
+	}
+	wd, _ := os.Getwd()
+	return wd
+}
+
+// RelativePath returns path relative to the workspace, or path unchanged when it cannot be
+// made relative or lies outside the workspace
+func RelativePath(path string) string {
+	if !filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	rel, err := filepath.Rel(Workspace(), path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
+
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*[a-zA-Z]")
+
+// StripANSI removes terminal color and style escape sequences
+func StripANSI(s string) string {
+	return ansiEscape.ReplaceAllString(s, "")
+}
+
+// escapeData escapes a workflow command message, see:
+// https://github.com/actions/toolkit/blob/main/packages/core/src/command.ts
+func escapeData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+// escapeProperty escapes a workflow command property value, which additionally can't contain
+// the ':' and ',' separators
+func escapeProperty(s string) string {
+	s = escapeData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
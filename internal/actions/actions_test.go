@@ -0,0 +1,126 @@
+package actions_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/anchore/go-make/internal/actions"
+	"github.com/anchore/go-make/require"
+)
+
+func TestAnnotation_String(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation actions.Annotation
+		want       string
+	}{
+		{
+			name:       "message only",
+			annotation: actions.Annotation{Level: "error", Message: "something failed"},
+			want:       "::error::something failed",
+		},
+		{
+			name:       "defaults to error",
+			annotation: actions.Annotation{File: "main.go", Line: 3, Message: "m"},
+			want:       "::error file=main.go,line=3::m",
+		},
+		{
+			name:       "file line and column",
+			annotation: actions.Annotation{Level: "warning", File: "pkg/file.go", Line: 12, Col: 5, Message: "unused variable"},
+			want:       "::warning file=pkg/file.go,line=12,col=5::unused variable",
+		},
+		{
+			name:       "escapes message and properties",
+			annotation: actions.Annotation{Level: "error", File: "a,b:c.go", Message: "100% broken\nsecond line"},
+			want:       "::error file=a%2Cb%3Ac.go::100%25 broken%0Asecond line",
+		},
+		{
+			name:       "strips colors",
+			annotation: actions.Annotation{Level: "error", Message: "\x1b[31mred\x1b[0m"},
+			want:       "::error::red",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.annotation.String())
+		})
+	}
+}
+
+func TestScanner(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "go.mod"), []byte("module github.com/org/repo\n\ngo 1.25\n"), 0o600))
+	t.Setenv("GITHUB_WORKSPACE", root)
+	t.Chdir(root)
+
+	tests := []struct {
+		name   string
+		output []string
+		want   []actions.Annotation
+	}{
+		{
+			name: "golangci-lint diagnostics",
+			output: []string{
+				"pkg/thing.go:12:5: ineffectual assignment to err (ineffassign)",
+				"	err = nil",
+				"	^",
+				"1 issues:",
+			},
+			want: []actions.Annotation{
+				{Level: "error", File: filepath.Join("pkg", "thing.go"), Line: 12, Col: 5, Message: "ineffectual assignment to err (ineffassign)"},
+			},
+		},
+		{
+			name: "go test failures resolve the package directory",
+			output: []string{
+				"--- FAIL: TestThing (0.00s)",
+				"    thing_test.go:20: expected 1, got 2",
+				"    --- FAIL: TestThing/sub (0.00s)",
+				"        thing_test.go:31: boom",
+				"FAIL",
+				"FAIL	github.com/org/repo/pkg	0.012s",
+				"ok  	github.com/org/repo/other	0.010s",
+			},
+			want: []actions.Annotation{
+				{Level: "error", File: filepath.Join("pkg", "thing_test.go"), Line: 20, Message: "TestThing: expected 1, got 2"},
+				{Level: "error", File: filepath.Join("pkg", "thing_test.go"), Line: 31, Message: "TestThing/sub: boom"},
+			},
+		},
+		{
+			name: "indented lines outside a test failure are ignored",
+			output: []string{
+				"=== RUN   TestThing",
+				"    thing_test.go:20: just logging",
+				"--- PASS: TestThing (0.00s)",
+			},
+		},
+		{
+			name: "duplicates are collapsed",
+			output: []string{
+				"a.go:1:1: same",
+				"a.go:1:1: same",
+			},
+			want: []actions.Annotation{
+				{Level: "error", File: "a.go", Line: 1, Col: 1, Message: "same"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := actions.Scanner{}
+			for _, line := range tt.output {
+				s.Line(line)
+			}
+			require.Equal(t, tt.want, s.Annotations())
+		})
+	}
+}
+
+func TestScanner_capsAnnotations(t *testing.T) {
+	s := actions.Scanner{}
+	for i := range actions.MaxAnnotations * 2 {
+		s.Line(filepath.Join("pkg", "file.go") + ":" + string(rune('1'+i%9)) + ":1: problem " + string(rune('a'+i)))
+	}
+	require.Equal(t, actions.MaxAnnotations, len(s.Annotations()))
+}
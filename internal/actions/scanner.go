@@ -0,0 +1,165 @@
+package actions
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MaxAnnotations caps the annotations collected by a Scanner; GitHub displays at most 10 error
+// annotations per step, so additional ones would only add noise to the log
+const MaxAnnotations = 10
+
+var (
+	// golangci-lint, go vet and compiler diagnostics, relative to the working directory:
+	//   path/to/file.go:12:5: message (linter)
+	diagnosticLine = regexp.MustCompile(`^(\S+\.go):(\d+):(\d+): (.+)$`)
+
+	// a failed go test, or subtest, whose details are indented on the following lines
+	testFailHeader = regexp.MustCompile(`^\s*--- FAIL: (\S+)`)
+
+	// a go test failure detail, relative to the package directory:
+	//       file_test.go:12: message
+	testFailureLine = regexp.MustCompile(`^\s+(\S+\.go):(\d+): (.+)$`)
+
+	// the package summary line that follows the failures in a package:
+	//   FAIL	github.com/org/repo/pkg	0.123s
+	packageFailLine = regexp.MustCompile(`^FAIL\s+(\S+)(\s|$)`)
+)
+
+// Scanner finds diagnostics in tool output, fed one line at a time, and collects them as
+// annotations. It understands golangci-lint (and go vet / compiler) file:line:col diagnostics
+// and go test failures. Scanner is safe for concurrent use, e.g. by stdout and stderr readers.
+type Scanner struct {
+	lock        sync.Mutex
+	annotations []Annotation
+	pending     []Annotation // go test failures, awaiting the package summary line
+	testName    string
+	seen        map[string]bool
+}
+
+// Line scans a single line of output
+func (s *Scanner) Line(line string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	line = StripANSI(line)
+	if m := testFailHeader.FindStringSubmatch(line); m != nil {
+		s.testName = m[1]
+		return
+	}
+	if m := testFailureLine.FindStringSubmatch(line); m != nil && s.testName != "" {
+		s.pending = append(s.pending, Annotation{
+			File:    m[1],
+			Line:    atoi(m[2]),
+			Message: s.testName + ": " + strings.TrimSpace(m[3]),
+		})
+		return
+	}
+	if m := packageFailLine.FindStringSubmatch(line); m != nil {
+		dir := packageDir(m[1])
+		for _, a := range s.pending {
+			if dir != "" {
+				a.File = RelativePath(filepath.Join(dir, a.File))
+			}
+			s.add(a)
+		}
+		s.pending = nil
+		s.testName = ""
+		return
+	}
+	if m := diagnosticLine.FindStringSubmatch(line); m != nil {
+		path := m[1]
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		s.add(Annotation{
+			File:    RelativePath(path),
+			Line:    atoi(m[2]),
+			Col:     atoi(m[3]),
+			Message: m[4],
+		})
+	}
+}
+
+// Annotations returns the collected annotations, including any go test failures whose
+// package summary was never seen
+func (s *Scanner) Annotations() []Annotation {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, a := range s.pending {
+		s.add(a)
+	}
+	s.pending = nil
+	return append([]Annotation(nil), s.annotations...)
+}
+
+func (s *Scanner) add(a Annotation) {
+	if len(s.annotations) >= MaxAnnotations {
+		return
+	}
+	if a.Level == "" {
+		a.Level = "error"
+	}
+	key := a.String()
+	if s.seen[key] {
+		return
+	}
+	if s.seen == nil {
+		s.seen = map[string]bool{}
+	}
+	s.seen[key] = true
+	s.annotations = append(s.annotations, a)
+}
+
+// packageDir resolves a Go import path to its directory within the main module, found by
+// searching upward from the working directory for go.mod. Returns "" when the package is not
+// part of the main module.
+func packageDir(pkg string) string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		if modPath := modulePath(filepath.Join(dir, "go.mod")); modPath != "" {
+			if pkg == modPath {
+				return dir
+			}
+			if rest, ok := strings.CutPrefix(pkg, modPath+"/"); ok {
+				return filepath.Join(dir, filepath.FromSlash(rest))
+			}
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// modulePath returns the module path declared in the go.mod file, or "" if it can't be read
+func modulePath(goMod string) string {
+	f, err := os.Open(goMod)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = f.Close() }()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
+
+func atoi(s string) int {
+	i, _ := strconv.Atoi(s)
+	return i
+}
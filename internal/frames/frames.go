@@ -0,0 +1,16 @@
+// Package frames identifies stack frames of go-make's own code, so errors are reported at the
+// build script line that called into go-make rather than somewhere inside it.
+package frames
+
+import "strings"
+
+const module = "github.com/anchore/go-make"
+
+// GoMake returns true when function, as named in a stack trace, is in a go-make package. Frames
+// in _test.go files are not, so go-make's own tests are reported as the caller.
+func GoMake(function, file string) bool {
+	if strings.HasSuffix(file, "_test.go") {
+		return false
+	}
+	return strings.HasPrefix(function, module+".") || strings.HasPrefix(function, module+"/")
+}
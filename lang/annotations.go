@@ -0,0 +1,60 @@
+package lang
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/internal/actions"
+	"github.com/anchore/go-make/internal/frames"
+	"github.com/anchore/go-make/redact"
+)
+
+// stackFrameLine matches the file line of a stack frame, e.g.: /path/to/.make/main.go:20 +0x1d
+var stackFrameLine = regexp.MustCompile(`^(\S+\.go):(\d+)`)
+
+// annotateError writes GitHub Actions ::error annotations for a failure: one for the first stack
+// frame within the workspace outside of go-make, typically the .make/main.go line that invoked
+// Run, followed by any diagnostics found in the failed command's output. Does nothing outside
+// GitHub Actions.
+func annotateError(err *StackTraceError) {
+	if !config.GitHubActions {
+		return
+	}
+	message, _, _ := strings.Cut(strings.TrimSpace(redact.Registered(fmt.Sprintf("%v", err.Err))), "\n")
+	annotation := actions.Annotation{Level: "error", Message: message}
+	annotation.File, annotation.Line = firstUserFrame(err.Stack)
+
+	_, _ = fmt.Fprintln(os.Stdout, annotation.String())
+	for _, a := range err.Annotations {
+		_, _ = fmt.Fprintln(os.Stdout, a)
+	}
+}
+
+// firstUserFrame returns the workspace-relative file and line of the first stack frame within
+// the workspace outside of go-make's packages, falling back to the first frame at all when none
+// are. Each frame is a function line followed by its file line.
+func firstUserFrame(stack []string) (file string, line int) {
+	fallbackFile, fallbackLine := "", 0
+	function := ""
+	for _, frame := range stack {
+		frame = strings.TrimSpace(frame)
+		m := stackFrameLine.FindStringSubmatch(frame)
+		if m == nil {
+			function = frame
+			continue
+		}
+		_, _ = fmt.Sscan(m[2], &line)
+		file = actions.RelativePath(m[1])
+		if fallbackFile == "" {
+			fallbackFile, fallbackLine = file, line
+		}
+		if !filepath.IsAbs(file) && !frames.GoMake(function, m[1]) {
+			return file, line
+		}
+	}
+	return fallbackFile, fallbackLine
+}
@@ -0,0 +1,78 @@
+package lang_test
+
+import (
+	"testing"
+
+	"github.com/anchore/go-make/lang"
+	"github.com/anchore/go-make/require"
+)
+
+func Test_firstUserFrame(t *testing.T) {
+	t.Setenv("GITHUB_WORKSPACE", "/work/repo")
+
+	tests := []struct {
+		name  string
+		stack []string
+		file  string
+		line  int
+	}{
+		{
+			name: "build script calling go-make",
+			stack: []string{
+				"github.com/anchore/go-make/run.Command({0x1, 0x2}, {0x3, 0x4, 0x5})",
+				"\t/home/user/go/pkg/mod/github.com/anchore/go-make@v1.0.0/run/run.go:40 +0x1d",
+				"github.com/anchore/go-make.Run({0x1, 0x2}, {0x0, 0x0, 0x0})",
+				"\t/home/user/go/pkg/mod/github.com/anchore/go-make@v1.0.0/run.go:20 +0x2e",
+				"main.main.func1()",
+				"\t/work/repo/.make/main.go:14 +0x3f",
+			},
+			file: ".make/main.go",
+			line: 14,
+		},
+		{
+			name: "go-make is the workspace",
+			stack: []string{
+				"github.com/anchore/go-make/run.Command({0x1, 0x2}, {0x3, 0x4, 0x5})",
+				"\t/work/repo/run/run.go:40 +0x1d",
+				"github.com/anchore/go-make/tasks/gotest.Tasks.func1()",
+				"\t/work/repo/tasks/gotest/test.go:63 +0x2e",
+				"main.main.func2()",
+				"\t/work/repo/.make/main.go:22 +0x3f",
+			},
+			file: ".make/main.go",
+			line: 22,
+		},
+		{
+			name: "go-make test",
+			stack: []string{
+				"github.com/anchore/go-make/run.Command({0x1, 0x2}, {0x3, 0x4, 0x5})",
+				"\t/work/repo/run/run.go:40 +0x1d",
+				"github.com/anchore/go-make/run.Test_Command(0xc000100000)",
+				"\t/work/repo/run/run_test.go:31 +0x3f",
+			},
+			file: "run/run_test.go",
+			line: 31,
+		},
+		{
+			name: "no frames in the workspace",
+			stack: []string{
+				"github.com/anchore/go-make/run.Command({0x1, 0x2}, {0x3, 0x4, 0x5})",
+				"\t/home/user/go/pkg/mod/github.com/anchore/go-make@v1.0.0/run/run.go:40 +0x1d",
+				"main.main()",
+				"\t/elsewhere/main.go:5 +0x3f",
+			},
+			file: "/home/user/go/pkg/mod/github.com/anchore/go-make@v1.0.0/run/run.go",
+			line: 40,
+		},
+		{
+			name: "empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, line := lang.FirstUserFrame(tt.stack)
+			require.Equal(t, tt.file, file)
+			require.Equal(t, tt.line, line)
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/anchore/go-make/color"
 	"github.com/anchore/go-make/config"
 	"github.com/anchore/go-make/log"
+	"github.com/anchore/go-make/redact"
 )
 
 // Throw panics if the provided error is non-nil. This is the fundamental error
@@ -39,6 +40,9 @@ type StackTraceError struct {
 	Stack []string
 	// Log contains additional output (e.g., stdout/stderr) to display with the error.
 	Log string
+	// Annotations contains GitHub Actions workflow commands (e.g. ::error file=...::) for
+	// diagnostics found in a failed command's output, emitted when running in GitHub Actions.
+	Annotations []string
 }
 
 func (s *StackTraceError) Unwrap() error {
@@ -54,8 +58,15 @@ func (s *StackTraceError) WithExitCode(exitCode int) *StackTraceError {
 	return s
 }
 
+// WithLog sets the additional output displayed with the error, masking any secrets
+// registered with redact.Register
 func (s *StackTraceError) WithLog(log string) *StackTraceError {
-	s.Log = log
+	s.Log = redact.Registered(log)
+	return s
+}
+
+func (s *StackTraceError) WithAnnotations(annotations ...string) *StackTraceError {
+	s.Annotations = append(s.Annotations, annotations...)
 	return s
 }
 
@@ -67,8 +78,11 @@ var _ error = (*StackTraceError)(nil)
 //
 // Behavior:
 //   - OkError: exits cleanly without error output
-//   - StackTraceError: prints formatted error with stack trace, exits with ExitCode
+//   - StackTraceError: prints formatted error with stack trace, exits with ExitCode; in
+//     GitHub Actions, also emits ::error annotations for the failure and its Annotations
 //   - Other panics: prints error with stack trace, exits with code 1
+//
+// Before exiting, the config.OnExit handlers are run, e.g. to stop background processes.
 func HandleErrors() {
 	v := recover()
 	if v == nil {
@@ -80,13 +94,20 @@ func HandleErrors() {
 	case *StackTraceError:
 		errText := strings.TrimSpace(fmt.Sprintf("ERROR: %v", v.Err))
 		log.Info("\n" + formatError(errText) + "\n\n" + strings.TrimSpace(v.Log) + "\n\n" + color.Grey("\n\n"+strings.Join(v.Stack, "\n")))
+		annotateError(v)
 		if v.ExitCode > 0 {
-			os.Exit(v.ExitCode)
+			exit(v.ExitCode)
 		}
 	default:
 		log.Info(formatError("ERROR: %v", v) + color.Grey("\n"+strings.Join(stackTraceLines(), "\n")))
 	}
-	os.Exit(1)
+	exit(1)
+}
+
+// exit runs the config.OnExit handlers, which deferred calls are skipped by os.Exit, then exits
+func exit(code int) {
+	config.DoExit()
+	os.Exit(code)
 }
 
 func formatError(format string, args ...any) string {
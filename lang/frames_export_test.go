@@ -0,0 +1,4 @@
+package lang
+
+// FirstUserFrame exposes firstUserFrame to the lang_test package, which can use require
+var FirstUserFrame = firstUserFrame
@@ -1,21 +1,58 @@
 package log
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/anchore/go-make/color"
 	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/redact"
 	"github.com/anchore/go-make/template"
 )
 
+// Output is where log lines are written, and where executed commands send stderr by default,
+// unless the context has another writer set with WithOutput
+var Output io.Writer = os.Stderr
+
+// Context returns the context log lines are currently written for, see Writer. The run package
+// sets this to run.Context, so log lines of a running task are labelled with the task name.
+var Context = context.Background
+
+type outputKey struct{}
+
+// WithOutput returns a copy of ctx writing log lines and command stderr to w, e.g. a writer
+// labelling each line with a task name (see stream.Prefix). Work started with the context, such
+// as commands and background processes, keeps writing to w.
+func WithOutput(ctx context.Context, w io.Writer) context.Context {
+	return context.WithValue(ctx, outputKey{}, w)
+}
+
+// Writer returns the writer set on ctx with WithOutput, or Output
+func Writer(ctx context.Context) io.Writer {
+	if w, ok := ctx.Value(outputKey{}).(io.Writer); ok {
+		return w
+	}
+	return Output
+}
+
+// CurrentOutput returns where log lines are currently written, labelled by the running task
+func CurrentOutput() io.Writer {
+	return Writer(Context())
+}
+
+// Prefix is prepended to every log line.
+//
+// Deprecated: task labels are applied per line by the task runner via WithOutput; Prefix is
+// retained for compatibility and is empty by default.
 var Prefix = ""
 
 var Info = func(format string, args ...any) {
 	if len(args) == 0 {
-		_, _ = os.Stderr.WriteString(Prefix + template.Render(format) + "\n")
+		write(Prefix + template.Render(format) + "\n")
 	} else {
-		_, _ = fmt.Fprintf(os.Stderr, Prefix+template.Render(format)+"\n", args...)
+		write(fmt.Sprintf(Prefix+template.Render(format)+"\n", args...))
 	}
 }
 
@@ -48,9 +85,14 @@ func init() {
 }
 
 func debugLogf(format string, args ...any) {
-	_, _ = fmt.Fprintf(os.Stderr, Prefix+color.Grey(template.Render(format))+"\n", args...)
+	write(fmt.Sprintf(Prefix+color.Grey(template.Render(format))+"\n", args...))
 }
 
 func traceLogf(format string, args ...any) {
-	_, _ = fmt.Fprintf(os.Stderr, Prefix+color.Grey(template.Render(format))+"\n", args...)
+	write(fmt.Sprintf(Prefix+color.Grey(template.Render(format))+"\n", args...))
+}
+
+// write writes a log line to CurrentOutput, masking any secrets registered with redact.Register
+func write(line string) {
+	_, _ = io.WriteString(CurrentOutput(), redact.Registered(line))
 }
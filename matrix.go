@@ -0,0 +1,152 @@
+package gomake
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/anchore/go-make/color"
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/lang"
+	"github.com/anchore/go-make/log"
+	"github.com/anchore/go-make/run"
+	"github.com/anchore/go-make/stream"
+	"github.com/anchore/go-make/template"
+)
+
+// expandMatrix creates one task instance per combination of the task's Matrix values, named
+// after the task with the values in sorted key order, e.g. build[arm64,linux] for
+// Matrix{"GOARCH": ..., "GOOS": ...}. Each instance runs the task's Run function with its
+// values set as template variables and exported environment variables.
+func expandMatrix(tsk *Task) []*Task {
+	if len(tsk.Matrix) == 0 || tsk.Run == nil {
+		return nil
+	}
+	keys := slices.Sorted(maps.Keys(tsk.Matrix))
+	combinations := []map[string]string{{}}
+	for _, key := range keys {
+		var next []map[string]string
+		for _, combination := range combinations {
+			for _, value := range tsk.Matrix[key] {
+				c := maps.Clone(combination)
+				c[key] = value
+				next = append(next, c)
+			}
+		}
+		combinations = next
+	}
+
+	for _, values := range combinations {
+		if len(values) == 0 {
+			continue
+		}
+		tsk.instances = append(tsk.instances, &Task{
+			Name:         tsk.Name + "[" + strings.Join(lang.Map(keys, func(k string) string { return values[k] }), ",") + "]",
+			Dependencies: tsk.Dependencies,
+			EnvPolicy:    tsk.EnvPolicy,
+			Run:          withMatrixValues(values, tsk.Run),
+			values:       values,
+		})
+	}
+	return tsk.instances
+}
+
+// withMatrixValues returns a function calling fn with the values set as template variables and
+// exported to commands, restoring the previous state afterward
+func withMatrixValues(values map[string]string, fn func()) func() {
+	return func() {
+		for _, key := range slices.Sorted(maps.Keys(values)) {
+			prev, hadPrev := template.Globals[key]
+			template.Globals[key] = values[key]
+			restoreExport := run.Export(key, values[key])
+			defer func() {
+				restoreExport()
+				if hadPrev {
+					template.Globals[key] = prev
+				} else {
+					delete(template.Globals, key)
+				}
+			}()
+		}
+		log.Debug("matrix values: %v", values)
+		fn()
+	}
+}
+
+// runMatrix runs all instances of a matrix task that haven't already run, one after another
+// or, for Parallel tasks, concurrently
+func (t *taskRunner) runMatrix(tsk *Task) {
+	var instances []*Task
+	for _, instance := range tsk.instances {
+		if t.run.Contains(instance) {
+			continue
+		}
+		t.run.Add(instance)
+		instances = append(instances, instance)
+	}
+
+	if !tsk.Parallel {
+		for _, instance := range instances {
+			runWithTaskOutput(instance)
+		}
+		return
+	}
+
+	var failed []string
+	lock := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	limit := make(chan struct{}, runtime.NumCPU())
+	for _, instance := range instances {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+
+			output, err := runInstanceProcess(instance, t.values)
+
+			lock.Lock()
+			defer lock.Unlock()
+			// write each instance's output in one piece so output and CI groups don't interleave
+			_, _ = log.Output.Write(output)
+			if err != nil {
+				failed = append(failed, instance.Name)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(failed) > 0 {
+		slices.Sort(failed)
+		panic(fmt.Errorf("matrix task %s failed: %s", color.Bold(tsk.Name), strings.Join(failed, ", ")))
+	}
+}
+
+// runInstanceProcess runs a single matrix instance in a new process of the current executable,
+// which keeps each instance's exported environment and template variables separate. The
+// instance's dependencies have already run, so the process skips them. KEY=VALUE command line
+// values are passed along. Returns the combined stdout and stderr output of the process.
+func runInstanceProcess(instance *Task, values map[string]string) ([]byte, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	// a TeeWriter serializes the stdout and stderr writes into the buffer
+	buf := &strings.Builder{}
+	out := stream.Tee(buf)
+	_, err = run.Command(executable,
+		run.Args(instance.Name),
+		run.Args(lang.Map(slices.Sorted(maps.Keys(values)), func(k string) string { return k + "=" + values[k] })...),
+		run.Env("GOMAKE_NO_DEPS", "true"),
+		run.Env("GOMAKE_ASSUME_YES", strconv.FormatBool(config.AssumeYes)),
+		run.Stdout(out),
+		run.Stderr(out),
+		run.Quiet(),
+	)
+	return []byte(buf.String()), err
+}
@@ -101,9 +101,11 @@ var urlUserinfo = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.\-]*://)[^/?#\s@:]+:[
 // Secrets scrubs known credential shapes out of an arbitrary string. Use it for
 // content that may contain secrets but isn't keyed by name — command output
 // captured on failure, fetched URLs, and the like. It is best-effort by design:
-// it masks the shapes go-make actually handles (GitHub tokens, Authorization
-// headers, URL userinfo) and cannot catch an arbitrary opaque secret.
+// it masks values added by Register and the shapes go-make actually handles
+// (GitHub tokens, Authorization headers, URL userinfo), and cannot catch an
+// arbitrary opaque secret that was never registered.
 func Secrets(s string) string {
+	s = Registered(s)
 	s = authzHeader.ReplaceAllString(s, `${1}`+Mask)
 	s = urlUserinfo.ReplaceAllString(s, `${1}`+Mask+`:`+Mask+`@`)
 	s = githubToken.ReplaceAllString(s, Mask)
@@ -1,8 +1,9 @@
-package redact
+package redact_test
 
 import (
 	"testing"
 
+	"github.com/anchore/go-make/redact"
 	"github.com/anchore/go-make/require"
 )
 
@@ -28,14 +29,14 @@ func TestIsSensitiveName(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			require.Equal(t, tt.want, IsSensitiveName(tt.input))
+			require.Equal(t, tt.want, redact.IsSensitiveName(tt.input))
 		})
 	}
 }
 
 func TestValue(t *testing.T) {
-	require.Equal(t, "***", Value("TAG_TOKEN", "ghp_secret"))
-	require.Equal(t, "plain", Value("GOPATH", "plain"))
+	require.Equal(t, "***", redact.Value("TAG_TOKEN", "ghp_secret"))
+	require.Equal(t, "plain", redact.Value("GOPATH", "plain"))
 }
 
 func TestArgs(t *testing.T) {
@@ -73,7 +74,7 @@ func TestArgs(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			require.Equal(t, tt.want, Args(tt.args))
+			require.Equal(t, tt.want, redact.Args(tt.args))
 		})
 	}
 }
@@ -118,7 +119,7 @@ func TestSecrets(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			require.Equal(t, tt.want, Secrets(tt.input))
+			require.Equal(t, tt.want, redact.Secrets(tt.input))
 		})
 	}
 }
@@ -0,0 +1,61 @@
+package redact
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/anchore/go-make/config"
+)
+
+// MinRegisteredLength is the length below which Register ignores a value: masking every
+// occurrence of a short string would mangle unrelated output while protecting nothing.
+const MinRegisteredLength = 8
+
+var (
+	registeredLock = &sync.RWMutex{}
+	// registered values, longest first so a value containing another is masked whole
+	registered []string
+)
+
+// Register adds secret values, such as tokens fetched at runtime, to be masked wherever
+// go-make prints: log output, command output attached to errors and anything else passed to
+// Registered or Secrets. Surrounding whitespace is ignored, as are values shorter than
+// MinRegisteredLength. When running in CI, values are also registered with GitHub Actions via
+// the ::add-mask:: workflow command, so the runner masks them in the rest of the job's log.
+func Register(values ...string) {
+	registeredLock.Lock()
+	defer registeredLock.Unlock()
+
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if len(value) < MinRegisteredLength || slices.Contains(registered, value) {
+			continue
+		}
+		registered = append(registered, value)
+		if config.CI {
+			// add-mask applies per line, so mask each line of a multi-line value
+			for _, line := range strings.Split(value, "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					_, _ = fmt.Fprintf(os.Stdout, "::add-mask::%s\n", line)
+				}
+			}
+		}
+	}
+	slices.SortStableFunc(registered, func(a, b string) int {
+		return len(b) - len(a)
+	})
+}
+
+// Registered masks all values added by Register in s
+func Registered(s string) string {
+	registeredLock.RLock()
+	defer registeredLock.RUnlock()
+
+	for _, value := range registered {
+		s = strings.ReplaceAll(s, value, Mask)
+	}
+	return s
+}
@@ -0,0 +1,43 @@
+package redact_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/redact"
+	"github.com/anchore/go-make/require"
+)
+
+func TestRegister(t *testing.T) {
+	require.Equal(t, "using s3cr3t-v4lue-1", redact.Registered("using s3cr3t-v4lue-1"))
+
+	redact.Register("  s3cr3t-v4lue-1\n", "short")
+	require.Equal(t, "using ***", redact.Registered("using s3cr3t-v4lue-1"))
+	// short values are not masked
+	require.Equal(t, "a short value", redact.Registered("a short value"))
+
+	// a value containing another registered value is masked whole
+	redact.Register("prefix-s3cr3t-v4lue-1-suffix")
+	require.Equal(t, "*** and ***", redact.Registered("prefix-s3cr3t-v4lue-1-suffix and s3cr3t-v4lue-1"))
+
+	// Secrets includes registered values
+	require.Equal(t, "value: ***", redact.Secrets("value: s3cr3t-v4lue-1"))
+}
+
+func TestRegister_CIAddsMask(t *testing.T) {
+	require.SetAndRestore(t, &config.CI, true)
+
+	stdout, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	require.NoError(t, err)
+	require.SetAndRestore(t, &os.Stdout, stdout)
+
+	redact.Register("multi-line-s3cr3t\nsecond-line", "multi-line-s3cr3t\nsecond-line")
+	require.NoError(t, stdout.Close())
+
+	contents, err := os.ReadFile(stdout.Name())
+	require.NoError(t, err)
+	// registered once, one mask per line
+	require.Equal(t, "::add-mask::multi-line-s3cr3t\n::add-mask::second-line\n", string(contents))
+}
@@ -1,6 +1,14 @@
 package gomake
 
 import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+
 	"github.com/anchore/go-make/binny"
 	"github.com/anchore/go-make/lang"
 	"github.com/anchore/go-make/run"
@@ -10,39 +18,188 @@ import (
 
 // Run executes a shell command with automatic template rendering and binny tool management.
 //
-// The command string is first parsed using shell.Split to handle quoted arguments properly,
-// then all parts are rendered through the template engine to expand variables like {{RootDir}}.
+// The command string is parsed with shell.ParseEnv, a subset of POSIX shell syntax, expanding
+// $VAR, ${VAR} and ${VAR:-default} outside of single quotes with values from the environment,
+// .env files and exported values (see run.LookupEnv). Then all words are rendered through the
+// template engine to expand variables like {{RootDir}}.
 // If the command references a binny-managed tool (configured in .binny.yaml), that tool
-// will be automatically installed if not already present.
+// will be automatically installed if not already present. No shell is run; the syntax is
+// implemented with run.Options:
+//   - NAME=value preceding a command sets it in the command's environment (run.Env)
+//   - unquoted arguments containing *, ? or [ are expanded as doublestar globs, in sorted
+//     order, and kept as-is when nothing matches
+//   - < file, > file, >> file, 2> file, 2>> file, 2>&1, >&2 and &> file redirect input and
+//     output; relative file names are relative to the current directory
+//   - commands separated by | form a pipeline, connected with OS pipes (see run.Pipe)
+//   - commands separated by && run when the previous command succeeded, || when it failed,
+//     and ; always, like a shell
 //
-// By default, Run panics on command failure. Use run.NoFail() to return an empty string
-// instead of panicking. Returns stdout as a trimmed string.
+// Options apply to each pipeline as with run.Pipe. By default, Run panics on command failure: the failure of
+// the last command run, so "a || b" does not fail when b succeeds. Use run.NoFail() to
+// return instead of panicking. Returns stdout as a trimmed string, the stdout of each command
+// run on its own line.
 //
 // Example:
 //
 //	Run(`go build -o {{ToolDir}}/myapp ./cmd/myapp`)
 //	Run(`golangci-lint run`, run.Quiet())
 //	version := Run(`git describe --tags`, run.NoFail())
+//	Run(`go list ./... | grep -v /test/ | xargs go vet`)
+//	Run(`CGO_ENABLED=0 go build ./... > build.log 2>&1 && echo ok`)
 func Run(cmd string, args ...run.Option) string {
-	cmdParts := parseCmd(cmd)
+	var out []string
+	var err error
+	failed := false
+	for _, step := range parseCmd(cmd) {
+		if (step.Op == "&&" && failed) || (step.Op == "||" && !failed) {
+			continue
+		}
+		result := runPipeline(step.Pipeline, args)
+		if result.Stdout != "" {
+			out = append(out, result.Stdout)
+		}
+		err = result.Err
+		failed = result.Err != nil || result.ExitCode != 0
+	}
+	return lang.Return(strings.Join(out, "\n"), err)
+}
 
-	// append command arguments in order, following the executable
-	if len(cmdParts) > 1 {
-		args = append([]run.Option{run.Args(cmdParts[1:]...)}, args...)
+// toolPath returns the absolute path to a binny-managed tool, installing or updating it as
+// needed, or cmd unchanged when it's not a managed tool
+func toolPath(cmd string) string {
+	if path := binny.ManagedToolPath(cmd); path != "" {
+		return path
 	}
+	return cmd
+}
 
-	// find absolute path to command, call binny to make sure it's up-to-date
-	cmd = binny.ManagedToolPath(cmdParts[0])
-	if cmd == "" {
-		cmd = cmdParts[0]
+// parseCmd parses the command line, expanding variables from the environment commands
+// receive, with all words, assignments and file names rendered as templates
+func parseCmd(cmd string) shell.Script {
+	// the environment is merged once for all the variables of the command
+	script := lang.Return(shell.ParseEnv(cmd, run.EnvLookup()))
+	if len(script) == 0 {
+		panic(fmt.Errorf("empty command: %q", cmd))
+	}
+	for _, step := range script {
+		for _, c := range step.Pipeline {
+			for i := range c.Env {
+				c.Env[i] = template.Render(c.Env[i])
+			}
+			for i := range c.Args {
+				c.Args[i].Value = template.Render(c.Args[i].Value)
+			}
+			for i := range c.Redirects {
+				if c.Redirects[i].Op != ">&" {
+					c.Redirects[i].Target = template.Render(c.Redirects[i].Target)
+				}
+			}
+		}
 	}
-	return lang.Return(run.Command(cmd, args...))
+	return script
 }
 
-func parseCmd(cmd ...string) []string {
-	cmd = append(shell.Split(cmd[0]), cmd[1:]...)
-	for i := range cmd {
-		cmd[i] = template.Render(cmd[i])
+// runPipeline runs the commands of a pipeline with the provided options, returning the result
+func runPipeline(pipeline []shell.Command, opts []run.Option) run.Result {
+	var files []*os.File
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+
+	var first []run.Option
+	var pipes []run.Option
+	for i, c := range pipeline {
+		cmdOpts := []run.Option{run.Args(expandArgs(c.Args[1:])...)}
+		if i == 0 {
+			cmdOpts = append(cmdOpts, opts...)
+		}
+		for _, env := range c.Env {
+			key, value, _ := strings.Cut(env, "=")
+			cmdOpts = append(cmdOpts, run.Env(key, value))
+		}
+		for _, r := range c.Redirects {
+			opt, f, err := redirectOption(r)
+			if f != nil {
+				files = append(files, f)
+			}
+			switch {
+			case err != nil:
+			case r.FD == 0 && i > 0:
+				err = fmt.Errorf("%s: input redirection is only supported for the first command of a pipeline", c.Args[0].Value)
+			case r.FD == 1 && r.Op != ">&" && i < len(pipeline)-1:
+				err = fmt.Errorf("%s: output redirection is only supported for the last command of a pipeline", c.Args[0].Value)
+			}
+			if err != nil {
+				return run.Result{Err: err}
+			}
+			if opt == nil {
+				continue
+			}
+			if r.FD == 1 && r.Op != ">&" && i > 0 {
+				// the output of the pipeline is written to the first command's stdout
+				first = append(first, opt)
+				continue
+			}
+			cmdOpts = append(cmdOpts, opt)
+		}
+		if i == 0 {
+			first = cmdOpts
+			continue
+		}
+		pipes = append(pipes, run.Pipe(toolPath(c.Args[0].Value), cmdOpts...))
 	}
-	return cmd
+
+	return run.CommandResult(toolPath(pipeline[0].Args[0].Value), append(first, pipes...)...)
+}
+
+// redirectOption returns the option for a redirection, along with the file it opened, or a nil
+// option for a redirection that changes nothing, such as 1>&1
+func redirectOption(r shell.Redirect) (run.Option, *os.File, error) {
+	switch {
+	case r.Op == ">&" && r.FD == 2 && r.Target == "1":
+		return run.StderrToStdout(), nil, nil
+	case r.Op == ">&" && r.FD == 1 && r.Target == "2":
+		return run.StdoutToStderr(), nil, nil
+	case r.Op == ">&" && strconv.Itoa(r.FD) == r.Target:
+		return nil, nil, nil
+	case r.Op == ">&" || (r.Op == "<") != (r.FD == 0):
+		return nil, nil, fmt.Errorf("unsupported redirection: %d%s%s", r.FD, r.Op, r.Target)
+	case r.Op == "<":
+		f, err := os.Open(r.Target)
+		if err != nil {
+			return nil, nil, err
+		}
+		return run.Stdin(f), f, nil
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if r.Op == ">>" {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	f, err := os.OpenFile(r.Target, flags, 0o644)
+	if err != nil {
+		return nil, nil, err
+	}
+	if r.FD == 2 {
+		return run.Stderr(f), f, nil
+	}
+	return run.Stdout(f), f, nil
+}
+
+// expandArgs returns the argument values, with globs expanded to the matching paths
+func expandArgs(words []shell.Word) []string {
+	var args []string
+	for _, word := range words {
+		if word.Glob {
+			if matches, _ := doublestar.FilepathGlob(word.Value); len(matches) > 0 {
+				sort.Strings(matches)
+				args = append(args, matches...)
+				continue
+			}
+		}
+		args = append(args, word.Value)
+	}
+	return args
 }
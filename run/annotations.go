@@ -0,0 +1,50 @@
+package run
+
+import (
+	"io"
+	"os/exec"
+
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/internal/actions"
+	"github.com/anchore/go-make/redact"
+	"github.com/anchore/go-make/stream"
+)
+
+// annotationScanner tees the command's stdout and stderr through an actions.Scanner when
+// running in GitHub Actions, so diagnostics in the output of a failed command can be surfaced
+// as annotations. The returned func flushes any partial trailing lines once the command has
+// completed. Returns a nil scanner, leaving the command untouched, outside GitHub Actions.
+func annotationScanner(cmd *exec.Cmd) (*actions.Scanner, func()) {
+	if !config.GitHubActions {
+		return nil, func() {}
+	}
+	scanner := &actions.Scanner{}
+	stdoutLines := stream.Lines(scanner.Line)
+	stderrLines := stream.Lines(scanner.Line)
+	cmd.Stdout = teeTo(cmd.Stdout, stdoutLines)
+	cmd.Stderr = teeTo(cmd.Stderr, stderrLines)
+	return scanner, func() {
+		_ = stdoutLines.Close()
+		_ = stderrLines.Close()
+	}
+}
+
+// annotations renders the scanner's annotations as workflow commands, scrubbed of known
+// secret shapes like the rest of the output attached to errors
+func annotations(scanner *actions.Scanner) []string {
+	if scanner == nil {
+		return nil
+	}
+	var out []string
+	for _, a := range scanner.Annotations() {
+		out = append(out, redact.Secrets(a.String()))
+	}
+	return out
+}
+
+func teeTo(w io.Writer, to io.Writer) io.Writer {
+	if w == nil {
+		return to
+	}
+	return stream.Tee(w, to)
+}
@@ -0,0 +1,210 @@
+package run
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/log"
+	"github.com/anchore/go-make/redact"
+	"github.com/anchore/go-make/template"
+)
+
+// AuditEntry is a record of an executed command, written as a line of the audit log
+type AuditEntry struct {
+	// Task is the name of the task running the command, if any
+	Task string `json:"task,omitempty"`
+
+	// Path is the resolved path of the executed binary
+	Path string `json:"path"`
+
+	// SHA256 is the hex-encoded digest of the executed binary, identifying the exact tool version
+	SHA256 string `json:"sha256,omitempty"`
+
+	// Args are the command arguments, with credential-looking values masked
+	Args []string `json:"args"`
+
+	// Dir is the working directory of the command
+	Dir string `json:"dir"`
+
+	// Env are the names of environment variables added or changed from the go-make process
+	// environment, e.g. with Env, Export and .env files
+	Env []string `json:"env,omitempty"`
+
+	// Start is when the command started
+	Start time.Time `json:"start"`
+
+	// DurationMs is how long the command ran, in milliseconds
+	DurationMs int64 `json:"duration_ms"`
+
+	// ExitCode is the exit code of the command, or -1 when it was not started or was killed
+	ExitCode int `json:"exit_code"`
+
+	// Error is set when the command could not be started
+	Error string `json:"error,omitempty"`
+}
+
+var (
+	auditLock     = &sync.Mutex{}
+	auditTask     = ""
+	auditDigests  = map[digestKey]string{}
+	auditWarnOnce = &sync.Once{}
+)
+
+// SetTaskName records the task name with commands written to the audit log, until the returned
+// restore function is called. Tasks set their name while they run.
+func SetTaskName(name string) (restore func()) {
+	auditLock.Lock()
+	defer auditLock.Unlock()
+
+	prev := auditTask
+	auditTask = name
+	return func() {
+		auditLock.Lock()
+		defer auditLock.Unlock()
+		auditTask = prev
+	}
+}
+
+// AuditFile returns the path of the audit log, which every executed command is appended to as a
+// line of JSON, see AuditEntry. Defaults to .tool/audit.jsonl, configurable with GOMAKE_AUDIT;
+// returns an empty string when disabled with GOMAKE_AUDIT=false.
+func AuditFile() (path string) {
+	if config.AuditFile == "" || config.AuditFile == "false" {
+		return ""
+	}
+	defer func() {
+		// tolerate template render panics by treating as no audit log
+		if recover() != nil {
+			path = ""
+		}
+	}()
+	return template.Render(config.AuditFile)
+}
+
+// RotateAuditLog moves the audit log aside to <file>.1, replacing an earlier one, so the audit log
+// records only the commands run from now on, such as those of a release build
+func RotateAuditLog() {
+	path := AuditFile()
+	if path == "" {
+		return
+	}
+	auditLock.Lock()
+	defer auditLock.Unlock()
+	if err := os.Rename(path, path+".1"); err != nil && !os.IsNotExist(err) {
+		log.Warn("unable to rotate audit log %s: %v", path, err)
+	}
+}
+
+// audit appends an entry for the completed command to the audit log
+func (e *execution) audit(exitCode int, duration time.Duration, startErr error) {
+	path := AuditFile()
+	if path == "" {
+		return
+	}
+	entry := AuditEntry{
+		Path:       e.c.Path,
+		Args:       redact.Args(e.c.Args[1:]),
+		Dir:        e.c.Dir,
+		Env:        addedEnv(e.c.Env),
+		Start:      e.started.UTC(),
+		DurationMs: duration.Milliseconds(),
+		ExitCode:   exitCode,
+	}
+	if entry.Dir == "" {
+		entry.Dir, _ = os.Getwd()
+	}
+	if startErr != nil {
+		entry.Error = redact.Secrets(startErr.Error())
+	}
+
+	auditLock.Lock()
+	defer auditLock.Unlock()
+
+	entry.Task = auditTask
+	entry.SHA256 = binaryDigest(entry.Path)
+	if err := appendAuditEntry(path, entry); err != nil {
+		auditWarnOnce.Do(func() {
+			log.Warn("unable to write audit log %s: %v", path, err)
+		})
+	}
+}
+
+func appendAuditEntry(path string, entry AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// a single append per entry, so parallel go-make processes don't interleave lines
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(line, '\n'))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+type digestKey struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// binaryDigest returns the sha256 of the file, cached by path, size and modification time so
+// tools run many times are hashed once
+func binaryDigest(path string) string {
+	stat, err := os.Stat(path)
+	if err != nil || !stat.Mode().IsRegular() {
+		return ""
+	}
+	key := digestKey{path: path, size: stat.Size(), modTime: stat.ModTime()}
+	if digest, ok := auditDigests[key]; ok {
+		return digest
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = f.Close() }()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return ""
+	}
+	digest := hex.EncodeToString(h.Sum(nil))
+	auditDigests[key] = digest
+	return digest
+}
+
+// addedEnv returns the sorted names of entries of env not in the go-make process environment
+func addedEnv(env []string) []string {
+	inherited := map[string]struct{}{}
+	for _, entry := range os.Environ() {
+		inherited[entry] = struct{}{}
+	}
+	var out []string
+	for _, entry := range env {
+		if _, ok := inherited[entry]; ok {
+			continue
+		}
+		name, _, _ := strings.Cut(entry, "=")
+		if !slices.Contains(out, name) {
+			out = append(out, name)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
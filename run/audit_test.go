@@ -0,0 +1,97 @@
+package run
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/redact"
+	"github.com/anchore/go-make/require"
+)
+
+func Test_audit(t *testing.T) {
+	testapp := buildTestApp(t)
+	auditFile := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+	require.SetAndRestore(t, &config.AuditFile, auditFile)
+
+	restore := SetTaskName("build")
+	_, err := Command(testapp, Args("exit-code", "3", "--token=abc123", "x"), Env("AUDIT_TEST_ADDED", "1"), Quiet())
+	require.Error(t, err)
+	restore()
+	_, err = Command(testapp, Args("stdout", "ok"), Quiet())
+	require.NoError(t, err)
+	_, err = Command(filepath.Join(t.TempDir(), "missing"), Quiet())
+	require.Error(t, err)
+
+	entries := readAuditEntries(t, auditFile)
+	require.Equal(t, 3, len(entries))
+
+	first := entries[0]
+	require.Equal(t, "build", first.Task)
+	require.Equal(t, testapp, first.Path)
+	require.Equal(t, 64, len(first.SHA256))
+	require.Equal(t, []string{"exit-code", "3", "--token=" + redact.Mask, "x"}, first.Args)
+	require.Contains(t, first.Env, "AUDIT_TEST_ADDED")
+	require.Equal(t, 3, first.ExitCode)
+	require.False(t, first.Start.IsZero())
+	wd, _ := os.Getwd()
+	require.Equal(t, wd, first.Dir)
+
+	second := entries[1]
+	require.Equal(t, "", second.Task)
+	// digests are identical for the same binary
+	require.Equal(t, first.SHA256, second.SHA256)
+	require.Equal(t, 0, second.ExitCode)
+
+	missing := entries[2]
+	require.Equal(t, -1, missing.ExitCode)
+	require.Equal(t, "", missing.SHA256)
+	require.NotEmpty(t, missing.Error)
+}
+
+func Test_RotateAuditLog(t *testing.T) {
+	testapp := buildTestApp(t)
+	auditFile := filepath.Join(t.TempDir(), "audit.jsonl")
+	require.SetAndRestore(t, &config.AuditFile, auditFile)
+
+	// nothing to rotate
+	RotateAuditLog()
+
+	_, err := Command(testapp, Args("stdout", "before"), Quiet())
+	require.NoError(t, err)
+	RotateAuditLog()
+	_, err = Command(testapp, Args("stdout", "after"), Quiet())
+	require.NoError(t, err)
+
+	entries := readAuditEntries(t, auditFile)
+	require.Equal(t, 1, len(entries))
+	require.Equal(t, []string{"stdout", "after"}, entries[0].Args)
+	rotated := readAuditEntries(t, auditFile+".1")
+	require.Equal(t, 1, len(rotated))
+	require.Equal(t, []string{"stdout", "before"}, rotated[0].Args)
+}
+
+func Test_AuditFile(t *testing.T) {
+	require.SetAndRestore(t, &config.AuditFile, "false")
+	require.Equal(t, "", AuditFile())
+
+	// a project root that can't be determined disables the audit log rather than failing
+	require.SetAndRestore(t, &config.AuditFile, "{{NotAFunction}}/audit.jsonl")
+	require.Equal(t, "", AuditFile())
+}
+
+func readAuditEntries(t *testing.T, path string) []AuditEntry {
+	t.Helper()
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var out []AuditEntry
+	for _, line := range strings.Split(strings.TrimSpace(string(contents)), "\n") {
+		var entry AuditEntry
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		out = append(out, entry)
+	}
+	return out
+}
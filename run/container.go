@@ -0,0 +1,165 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/file"
+	"github.com/anchore/go-make/template"
+)
+
+// ContainerOption configures how InContainer runs a command
+type ContainerOption func(*containerConfig)
+
+type containerConfig struct {
+	image   string
+	engine  string
+	user    *string
+	volumes []string
+	args    []string
+	tty     bool
+}
+
+// Engine sets the docker CLI compatible command used to run the container, such as podman,
+// overriding config.ContainerEngine (GOMAKE_CONTAINER_ENGINE)
+func Engine(engine string) ContainerOption {
+	return func(c *containerConfig) {
+		c.engine = engine
+	}
+}
+
+// Volume mounts a host path into the container, in addition to the project root and tool directory
+func Volume(hostPath, containerPath string) ContainerOption {
+	return func(c *containerConfig) {
+		c.volumes = append(c.volumes, hostPath+":"+containerPath)
+	}
+}
+
+// User sets the user the command runs as in the container, such as "root". By default, the
+// command runs as the current user's UID and GID, so files written to mounted directories are
+// owned by the current user. An empty user leaves the image's default user.
+func User(user string) ContainerOption {
+	return func(c *containerConfig) {
+		c.user = &user
+	}
+}
+
+// EngineArgs adds arguments to the engine's run command, such as "--pull", "never"
+func EngineArgs(args ...string) ContainerOption {
+	return func(c *containerConfig) {
+		c.args = append(c.args, args...)
+	}
+}
+
+// InContainer runs the command inside a container from the given image instead of on the host,
+// using config.ContainerEngine (GOMAKE_CONTAINER_ENGINE, docker by default). The project root
+// and tool directory are mounted at the same paths as on the host, and the working directory is
+// the same, so paths in arguments work unchanged. The environment the command would receive
+// on the host, including .env values and Env options, is passed to the container, except
+// host-specific variables such as PATH and HOME.
+//
+// Example:
+//
+//	Run(`go test ./...`, run.InContainer("golang:1.25", run.EngineArgs("--pull", "never")))
+func InContainer(image string, opts ...ContainerOption) Option {
+	return func(ctx context.Context, _ *exec.Cmd) error {
+		cfg, _ := ctx.Value(runConfigKey{}).(*runConfig)
+		if cfg != nil {
+			cfg.container = &containerConfig{image: image, engine: config.ContainerEngine}
+			for _, opt := range opts {
+				opt(cfg.container)
+			}
+		}
+		return nil
+	}
+}
+
+// hostEnvVars are not passed to containers, as their host values don't apply inside the container
+var hostEnvVars = []string{"PATH", "HOME", "USER", "LOGNAME", "SHELL", "HOSTNAME", "PWD", "OLDPWD", "SHLVL", "TMPDIR", "TEMP", "TMP", "_"}
+
+// inContainer rewrites cmd, after all options have been applied, to run in the configured
+// container. Environment values are passed to the engine process rather than the command
+// line, so they aren't logged or visible in the process list.
+func (c *containerConfig) inContainer(cmd *exec.Cmd) error {
+	engine, err := exec.LookPath(c.engine)
+	if err != nil {
+		return fmt.Errorf("container engine %q not found, install it or set GOMAKE_CONTAINER_ENGINE: %w", c.engine, err)
+	}
+
+	workDir := cmd.Dir
+	if workDir == "" {
+		workDir = file.Cwd()
+	}
+	workDir, err = filepath.Abs(workDir)
+	if err != nil {
+		return err
+	}
+	rootDir, err := filepath.Abs(template.Render(config.RootDir))
+	if err != nil {
+		return err
+	}
+	toolDir, err := filepath.Abs(template.Render(config.ToolDir))
+	if err != nil {
+		return err
+	}
+
+	args := []string{c.engine, "run", "--rm", "-v", rootDir + ":" + rootDir}
+	if !isWithin(toolDir, rootDir) && file.Exists(toolDir) {
+		args = append(args, "-v", toolDir+":"+toolDir)
+	}
+	for _, volume := range c.volumes {
+		args = append(args, "-v", volume)
+	}
+	args = append(args, "-w", workDir)
+	if user := c.containerUser(); user != "" {
+		args = append(args, "--user", user)
+	}
+	if cmd.Stdin != nil {
+		args = append(args, "-i")
+	}
+	if c.tty {
+		args = append(args, "-t")
+	}
+	var passed []string
+	for _, entry := range cmd.Env {
+		name, _, _ := strings.Cut(entry, "=")
+		if name == "" || slices.Contains(hostEnvVars, name) || slices.Contains(passed, name) {
+			continue
+		}
+		passed = append(passed, name)
+		args = append(args, "-e", name)
+	}
+	args = append(args, c.args...)
+	args = append(args, c.image)
+	args = append(args, cmd.Args...)
+
+	cmd.Path = engine
+	cmd.Args = args
+	// the command itself may not exist on the host, only in the container
+	cmd.Err = nil
+	// the command runs in the container's working directory, the engine may run from anywhere
+	cmd.Dir = ""
+	return nil
+}
+
+// containerUser returns the user to run as, the current UID:GID unless set with User
+func (c *containerConfig) containerUser() string {
+	if c.user != nil {
+		return *c.user
+	}
+	if config.Windows {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid())
+}
+
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,81 @@
+package run
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/require"
+)
+
+func Test_InContainer(t *testing.T) {
+	engine := filepath.Join(t.TempDir(), "fakeengine")
+	if config.Windows {
+		engine += ".exe"
+	}
+	_, err := Command("go", Args("build", "-C", filepath.Join("testdata", "fakeengine"), "-o", engine, "."))
+	require.NoError(t, err)
+
+	rootDir := t.TempDir()
+	toolDir := t.TempDir()
+	workDir := filepath.Join(rootDir, "sub")
+	require.NoError(t, os.Mkdir(workDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, ".env"), []byte("CONTAINER_TEST_DOTENV=from-dotenv\n"), 0o600))
+	require.SetAndRestore(t, &config.RootDir, rootDir)
+	require.SetAndRestore(t, &config.ToolDir, toolDir)
+	require.SetAndRestore(t, &dotEnvOnce, &sync.Once{})
+	require.SetAndRestore(t, &dotEnvCache, map[string]string(nil))
+	t.Setenv("CONTAINER_TEST_PROCESS", "from-process")
+	t.Setenv("GOPATH", "/host/go")
+
+	out, err := Command("not-on-the-host", Args("some", "args"),
+		InContainer("some-image:latest", Engine(engine), User("1000:1000"), Volume("/host", "/container"), EngineArgs("--pull", "never")),
+		InDir(workDir),
+		Env("CONTAINER_TEST_OPTION", "from-option"),
+	)
+	require.NoError(t, err)
+	args := strings.Split(out, "\n")
+
+	// the engine runs the image with the original command, which doesn't need to exist on the host
+	require.Equal(t, []string{"run", "--rm", "-v", rootDir + ":" + rootDir, "-v", toolDir + ":" + toolDir, "-v", "/host:/container",
+		"-w", workDir, "--user", "1000:1000"}, args[:12])
+	tail := args[slices.Index(args, "--pull"):]
+	require.Equal(t, []string{"--pull", "never", "some-image:latest", "not-on-the-host", "some", "args"}, tail[:6])
+
+	// the environment is passed by name, with values set on the engine process
+	require.Contains(t, args, "CONTAINER_TEST_DOTENV=from-dotenv")
+	require.Contains(t, args, "CONTAINER_TEST_PROCESS=from-process")
+	require.Contains(t, args, "CONTAINER_TEST_OPTION=from-option")
+	require.False(t, slices.Contains(args, "GOPATH"))
+	require.False(t, slices.Contains(args, "PATH"))
+	require.False(t, slices.Contains(args, "-t"))
+
+	// the engine allocates a terminal in the container when running under one
+	if terminalSupported {
+		out, err = Command("not-on-the-host", InContainer("some-image:latest", Engine(engine)), TTY(), Quiet())
+		require.NoError(t, err)
+		require.Contains(t, strings.Split(out, "\n"), "-t")
+	}
+}
+
+func Test_InContainer_defaultUser(t *testing.T) {
+	if config.Windows {
+		t.Skip("no UID mapping on windows")
+	}
+	c := containerConfig{}
+	require.Equal(t, fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()), c.containerUser())
+	root := ""
+	c.user = &root
+	require.Equal(t, "", c.containerUser())
+}
+
+func Test_InContainer_missingEngine(t *testing.T) {
+	_, err := Command("go", InContainer("image", Engine("definitely-not-a-container-engine")))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "GOMAKE_CONTAINER_ENGINE")
+}
@@ -2,7 +2,10 @@ package run
 
 import (
 	"context"
+	"io"
 	"sync"
+
+	"github.com/anchore/go-make/log"
 )
 
 var (
@@ -10,6 +13,11 @@ var (
 	currentContext, cancel = context.WithCancel(context.Background())
 )
 
+func init() {
+	// log lines are written to the output of the current context, labelled by task
+	log.Context = Context
+}
+
 // Context returns the current context being used for executing scripts
 func Context() context.Context {
 	contextLock.Lock()
@@ -31,3 +39,22 @@ func Cancel() {
 	cancel()
 	currentContext, cancel = context.WithCancel(context.Background()) //nolint:gosec // G118: cancel is called on next Cancel() invocation
 }
+
+// SetOutput writes log lines and the stderr of commands started with the current context to w,
+// see log.WithOutput, until the returned restore function is called. Commands and background
+// processes already started keep writing to their previous output.
+func SetOutput(w io.Writer) (restore func()) {
+	contextLock.Lock()
+	defer contextLock.Unlock()
+	previous := currentContext
+	outputContext := log.WithOutput(previous, w)
+	currentContext = outputContext
+	return func() {
+		contextLock.Lock()
+		defer contextLock.Unlock()
+		// unless cancelled in the meantime, which replaces the context
+		if currentContext == outputContext {
+			currentContext = previous
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package run
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"os/exec"
@@ -11,6 +10,7 @@ import (
 
 	"github.com/anchore/go-make/config"
 	"github.com/anchore/go-make/log"
+	"github.com/anchore/go-make/redact"
 	"github.com/anchore/go-make/template"
 )
 
@@ -31,16 +31,39 @@ var (
 	gitCheckIgnore = runGitCheckIgnore
 )
 
-// loadDotEnv resolves and parses <RootDir>/.env exactly once per process.
-// Missing files are silent; op-resolution failures fall back to raw values
-// with a warning. See readDotEnv for details.
+// loadDotEnv resolves and parses the project's .env files exactly once per process, see
+// DotEnvFiles. Later files override earlier ones, and values may refer to values of earlier
+// files. Missing files are silent; op-resolution failures fall back to raw values with a
+// warning. See readDotEnv for details.
 func loadDotEnv() map[string]string {
 	dotEnvOnce.Do(func() {
-		dotEnvCache = readDotEnv(dotEnvPath())
+		values := map[string]string{}
+		for _, path := range DotEnvFiles() {
+			for key, value := range readDotEnv(path, values) {
+				values[key] = value
+			}
+		}
+		dotEnvCache = values
 	})
 	return dotEnvCache
 }
 
+// DotEnvFiles returns the paths of the .env files loaded for commands, in the order they are
+// layered, each overriding values of the previous: .env, .env.local and, when a profile is
+// selected with GOMAKE_ENV, .env.<profile> and .env.<profile>.local, all in the project root.
+// Files that don't exist are included.
+func DotEnvFiles() []string {
+	base := dotEnvPath()
+	if base == "" {
+		return nil
+	}
+	files := []string{base, base + ".local"}
+	if config.EnvProfile != "" {
+		files = append(files, base+"."+config.EnvProfile, base+"."+config.EnvProfile+".local")
+	}
+	return files
+}
+
 // dotEnvPath returns <RootDir>/.env, tolerating a template render failure
 // (e.g., GitRoot not registered when the git package isn't imported).
 func dotEnvPath() (path string) {
@@ -51,11 +74,28 @@ func dotEnvPath() (path string) {
 	return filepath.Join(template.Render(config.RootDir), ".env")
 }
 
-// readDotEnv reads and parses the .env file at the given path. A missing
-// path returns an empty map (no error). If the file contains op:// refs,
-// op inject is invoked over its contents; on failure the raw content is
-// parsed instead and a warning is logged.
-func readDotEnv(path string) map[string]string {
+// DotEnvStatus reports the location of the project's .env file, whether it exists and whether
+// it is gitignored, which is required for its values to be loaded
+func DotEnvStatus() (path string, exists, ignored bool) {
+	path = dotEnvPath()
+	if path == "" {
+		return "", false, false
+	}
+	if _, err := os.Stat(path); err != nil {
+		return path, false, false
+	}
+	return path, true, gitCheckIgnore(path)
+}
+
+// readDotEnv reads and parses the .env file at the given path, interpolating values from the
+// earlier values and the process environment. A missing path returns an empty map (no error).
+// If the file contains op:// refs, op inject is invoked over its contents; on failure the raw
+// content is parsed instead and a warning is logged. Values referencing other secret stores are
+// resolved with the resolver registered for their scheme, see RegisterSecretResolver.
+//
+// The .env and *.local files often hold secrets, so they are refused unless gitignored. Other
+// files, such as .env.<profile>, hold per-environment settings that may be committed.
+func readDotEnv(path string, earlier map[string]string) map[string]string {
 	if path == "" {
 		return map[string]string{}
 	}
@@ -70,21 +110,35 @@ func readDotEnv(path string) map[string]string {
 	// safety gate: refuse to load if the file is not gitignored. catches the
 	// common foot-gun of committing secrets. indeterminate results (no git,
 	// not a repo) are treated as allowed so non-git workflows still function.
-	if !gitCheckIgnore(path) {
+	if requiresGitIgnore(path) && !gitCheckIgnore(path) {
 		log.Warn("dotenv: %s is not gitignored; refusing to load (add it to .gitignore to enable)", path)
 		return map[string]string{}
 	}
+	log.Trace("dotenv: loading %s", path)
 
 	content := string(raw)
-	if strings.Contains(content, opRefPrefix) {
-		rendered, opErr := opInject(content)
-		if opErr != nil {
-			log.Warn("dotenv: op inject failed: %v; using raw values", opErr)
-		} else {
-			content = rendered
+	if !strings.Contains(content, opRefPrefix) {
+		return parseDotEnvWith(content, earlier, resolveSecret)
+	}
+	rendered, opErr := opInject(content)
+	if opErr != nil {
+		log.Warn("dotenv: op inject failed: %v; using raw values", opErr)
+		return parseDotEnvWith(content, earlier, resolveSecret)
+	}
+	values := parseDotEnvWith(rendered, earlier, resolveSecret)
+	// values resolved from a secret store are secrets: mask them wherever go-make prints
+	for key, rawValue := range parseDotEnvWith(content, earlier, nil) {
+		if strings.Contains(rawValue, opRefPrefix) {
+			redact.Register(values[key])
 		}
 	}
-	return parseDotEnv(content)
+	return values
+}
+
+// requiresGitIgnore returns true for .env files expected to hold secrets: .env and *.local
+func requiresGitIgnore(path string) bool {
+	name := filepath.Base(path)
+	return name == ".env" || strings.HasSuffix(name, ".local")
 }
 
 // runGitCheckIgnore asks git whether path is gitignored. Returns true if
@@ -119,17 +173,47 @@ func runOpInject(content string) (string, error) {
 	return string(out), nil
 }
 
-// parseDotEnv parses a .env-style document into a map. Supports:
+// parseDotEnv parses a .env-style document into a map, interpolating from the process
+// environment. See parseDotEnvWith.
+func parseDotEnv(content string) map[string]string {
+	return parseDotEnvWith(content, nil, nil)
+}
+
+// parseDotEnvWith parses a .env-style document into a map. Supports:
 //   - blank lines and full-line comments starting with #
 //   - optional `export ` prefix
-//   - optional surrounding single or double quotes on values
+//   - unquoted values, with trailing ` # comments` removed
+//   - double-quoted values, which may span lines and contain \n, \t, \r, \", \\ and \$ escapes
+//   - single-quoted values, which may span lines and are taken literally
+//   - ${VAR}, $VAR, ${VAR:-default} (unset or empty) and ${VAR-default} (unset) interpolation
+//     in unquoted and double-quoted values, from the process environment, which wins on
+//     conflict like for commands, then earlier entries of the document, then the values of
+//     earlier .env files
 //
-// Does not perform ${VAR} interpolation.
-func parseDotEnv(content string) map[string]string {
+// When resolveRef is set, each value is passed through it before being stored, so secret
+// references are resolved before later entries interpolate them.
+func parseDotEnvWith(content string, earlier map[string]string, resolveRef func(key, value string) string) map[string]string {
 	out := map[string]string{}
-	scanner := bufio.NewScanner(strings.NewReader(content))
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+	set := func(key, value string) {
+		if resolveRef != nil {
+			value = resolveRef(key, value)
+		}
+		out[key] = value
+	}
+	resolve := func(key string) (string, bool) {
+		if value, ok := os.LookupEnv(key); ok {
+			return value, true
+		}
+		if value, ok := out[key]; ok {
+			return value, true
+		}
+		value, ok := earlier[key]
+		return value, ok
+	}
+
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
@@ -140,17 +224,137 @@ func parseDotEnv(content string) map[string]string {
 		}
 		key := strings.TrimSpace(line[:eq])
 		val := strings.TrimSpace(line[eq+1:])
-		if len(val) >= 2 {
-			first, last := val[0], val[len(val)-1]
-			if (first == '"' && last == '"') || (first == '\'' && last == '\'') {
-				val = val[1 : len(val)-1]
+
+		if val != "" && (val[0] == '"' || val[0] == '\'') {
+			quote := val[0]
+			// a quoted value continues on the following lines until the closing quote
+			body := val[1:]
+			end := closingQuote(body, quote)
+			for end < 0 && i+1 < len(lines) {
+				i++
+				body += "\n" + lines[i]
+				end = closingQuote(body, quote)
+			}
+			if end < 0 {
+				log.Warn("dotenv: unterminated quoted value for %s", key)
+				end = len(body)
+			}
+			body = body[:end]
+			if quote == '"' {
+				set(key, expandDotEnv(body, true, resolve))
+			} else {
+				set(key, body)
+			}
+			continue
+		}
+
+		// unquoted values end at a comment preceded by whitespace
+		for _, sep := range []string{" #", "\t#"} {
+			if idx := strings.Index(val, sep); idx >= 0 {
+				val = strings.TrimSpace(val[:idx])
 			}
 		}
-		out[key] = val
+		set(key, expandDotEnv(val, false, resolve))
 	}
 	return out
 }
 
+// closingQuote returns the index of the quote ending a value, skipping escaped double quotes,
+// or -1 when not found
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// expandDotEnv interpolates variables in a value, and with escapes, also processes the escape
+// sequences of double-quoted values. \$ is a literal $ in both.
+func expandDotEnv(s string, escapes bool, lookup func(string) (string, bool)) string {
+	out := strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ch == '\\' && i+1 < len(s) {
+			next := s[i+1]
+			replacement, ok := map[byte]string{'$': "$"}[next]
+			if escapes {
+				switch next {
+				case 'n':
+					replacement, ok = "\n", true
+				case 't':
+					replacement, ok = "\t", true
+				case 'r':
+					replacement, ok = "\r", true
+				case '"', '\\':
+					replacement, ok = string(next), true
+				}
+			}
+			if ok {
+				out.WriteString(replacement)
+				i++
+				continue
+			}
+		}
+		if ch != '$' || i+1 >= len(s) {
+			out.WriteByte(ch)
+			continue
+		}
+		if s[i+1] == '{' {
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				out.WriteByte(ch)
+				continue
+			}
+			out.WriteString(expandDotEnvReference(s[i+2:i+2+end], escapes, lookup))
+			i += end + 2
+			continue
+		}
+		name := dotEnvName(s[i+1:])
+		if name == "" {
+			out.WriteByte(ch)
+			continue
+		}
+		value, _ := lookup(name)
+		out.WriteString(value)
+		i += len(name)
+	}
+	return out.String()
+}
+
+// expandDotEnvReference resolves the contents of a ${...} reference: NAME, NAME:-default or
+// NAME-default
+func expandDotEnvReference(ref string, escapes bool, lookup func(string) (string, bool)) string {
+	name := dotEnvName(ref)
+	value, set := lookup(name)
+	switch rest := ref[len(name):]; {
+	case strings.HasPrefix(rest, ":-"):
+		if value == "" {
+			return expandDotEnv(rest[2:], escapes, lookup)
+		}
+	case strings.HasPrefix(rest, "-"):
+		if !set {
+			return expandDotEnv(rest[1:], escapes, lookup)
+		}
+	}
+	return value
+}
+
+// dotEnvName returns the variable name at the start of s
+func dotEnvName(s string) string {
+	for i, ch := range s {
+		if ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (i > 0 && ch >= '0' && ch <= '9') {
+			continue
+		}
+		return s[:i]
+	}
+	return s
+}
+
 // mergeDotEnv appends entries from dotEnv to env only for keys not already
 // present (process env wins). Returns the merged slice and any keys that
 // were skipped due to existing values, for trace logging.
@@ -5,12 +5,16 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
 
 	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/log"
+	"github.com/anchore/go-make/redact"
 	"github.com/anchore/go-make/require"
+	"github.com/anchore/go-make/template"
 )
 
 func TestParseDotEnv(t *testing.T) {
@@ -85,6 +89,58 @@ BAZ=qux
 			input:    "  FOO   =   bar  ",
 			expected: map[string]string{"FOO": "bar"},
 		},
+		{
+			name:     "inline comment after unquoted value",
+			input:    "FOO=bar # the bar\nBAZ=a#b",
+			expected: map[string]string{"FOO": "bar", "BAZ": "a#b"},
+		},
+		{
+			name:     "hash inside quotes is kept",
+			input:    `FOO="bar # not a comment"`,
+			expected: map[string]string{"FOO": "bar # not a comment"},
+		},
+		{
+			name:     "escapes in double-quoted value",
+			input:    `FOO="line1\nline2\t\"quoted\" \\ \$HOME"`,
+			expected: map[string]string{"FOO": "line1\nline2\t\"quoted\" \\ $HOME"},
+		},
+		{
+			name:     "single-quoted value is literal",
+			input:    `FOO='a\nb $HOME ${X}'`,
+			expected: map[string]string{"FOO": `a\nb $HOME ${X}`},
+		},
+		{
+			name:     "multiline double-quoted value",
+			input:    "KEY=\"-----BEGIN KEY-----\nabc\n-----END KEY-----\"\nNEXT=1",
+			expected: map[string]string{"KEY": "-----BEGIN KEY-----\nabc\n-----END KEY-----", "NEXT": "1"},
+		},
+		{
+			name:     "multiline single-quoted value",
+			input:    "FOO='a\n  b'\nBAR=2",
+			expected: map[string]string{"FOO": "a\n  b", "BAR": "2"},
+		},
+		{
+			name:     "interpolates earlier entries",
+			input:    "HOST=localhost\nPORT=8080\nURL=http://${HOST}:$PORT/\nQUOTED=\"$HOST\"",
+			expected: map[string]string{"HOST": "localhost", "PORT": "8080", "URL": "http://localhost:8080/", "QUOTED": "localhost"},
+		},
+		{
+			name:  "interpolation defaults",
+			input: "EMPTY=\nA=${DOTENV_TEST_UNSET:-one}\nB=${EMPTY:-two}\nC=${EMPTY-three}\nD=${DOTENV_TEST_UNSET-four}\nE=${DOTENV_TEST_UNSET}",
+			expected: map[string]string{
+				"EMPTY": "",
+				"A":     "one",
+				"B":     "two",
+				"C":     "",
+				"D":     "four",
+				"E":     "",
+			},
+		},
+		{
+			name:     "escaped dollar is not interpolated",
+			input:    `PRICE=\$5`,
+			expected: map[string]string{"PRICE": "$5"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -98,6 +154,66 @@ BAZ=qux
 	}
 }
 
+func TestParseDotEnv_ProcessEnvInterpolation(t *testing.T) {
+	t.Setenv("DOTENV_TEST_USER", "process")
+
+	got := parseDotEnvWith("DOTENV_TEST_USER=file\nGREETING=hello ${DOTENV_TEST_USER}", nil, nil)
+	// the process env wins, as it does for the values commands receive
+	require.Equal(t, "hello process", got["GREETING"])
+}
+
+func TestParseDotEnv_EarlierLayerInterpolation(t *testing.T) {
+	earlier := map[string]string{"DOTENV_TEST_A": "1", "DOTENV_TEST_C": "3"}
+
+	got := parseDotEnvWith("DOTENV_TEST_A=2\nDOTENV_TEST_B=${DOTENV_TEST_A}\nDOTENV_TEST_D=${DOTENV_TEST_C}", earlier, nil)
+	// a value of the file itself wins over earlier files
+	require.Equal(t, "2", got["DOTENV_TEST_B"])
+	require.Equal(t, "3", got["DOTENV_TEST_D"])
+}
+
+func TestLoadDotEnv_Layers(t *testing.T) {
+	rootDir := t.TempDir()
+	files := map[string]string{
+		".env":               "A=base\nB=base\nC=base\nD=base\nURL=http://${A}",
+		".env.local":         "B=local\nLOCAL_B=${B}\nLOCAL_URL=${URL}/local",
+		".env.staging":       "C=staging\nD=${B}-staging",
+		".env.staging.local": "A=staging-local",
+		".env.production":    "A=production",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(rootDir, name), []byte(content), 0o600))
+	}
+
+	require.SetAndRestore(t, &config.RootDir, rootDir)
+	require.SetAndRestore(t, &config.EnvProfile, "staging")
+	require.SetAndRestore(t, &gitCheckIgnore, func(string) bool { return true })
+	require.SetAndRestore(t, &dotEnvOnce, &sync.Once{})
+	require.SetAndRestore(t, &dotEnvCache, map[string]string(nil))
+
+	got := loadDotEnv()
+	require.Equal(t, map[string]string{
+		"A":         "staging-local",
+		"B":         "local",
+		"C":         "staging",
+		"D":         "local-staging",
+		"LOCAL_B":   "local",
+		"URL":       "http://base",
+		"LOCAL_URL": "http://base/local",
+	}, got)
+}
+
+func TestReadDotEnv_ProfileFileNeedNotBeGitignored(t *testing.T) {
+	dir := t.TempDir()
+	profile := filepath.Join(dir, ".env.ci")
+	local := filepath.Join(dir, ".env.ci.local")
+	require.NoError(t, os.WriteFile(profile, []byte("FOO=bar\n"), 0o600))
+	require.NoError(t, os.WriteFile(local, []byte("FOO=bar\n"), 0o600))
+	require.SetAndRestore(t, &gitCheckIgnore, func(string) bool { return false })
+
+	require.Equal(t, "bar", readDotEnv(profile, nil)["FOO"])
+	require.Equal(t, 0, len(readDotEnv(local, nil)))
+}
+
 func TestMergeDotEnv(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -154,7 +270,7 @@ func TestReadDotEnv_RefusesWhenNotGitignored(t *testing.T) {
 		t.Fatalf("opInject must not be called when load is refused")
 		return "", nil
 	})
-	got := readDotEnv(path)
+	got := readDotEnv(path, nil)
 	require.Equal(t, 0, len(got))
 }
 
@@ -165,7 +281,7 @@ func TestReadDotEnv_LoadsWhenGitignored(t *testing.T) {
 		t.Fatalf("write: %v", err)
 	}
 	require.SetAndRestore(t, &gitCheckIgnore, func(string) bool { return true })
-	got := readDotEnv(path)
+	got := readDotEnv(path, nil)
 	require.Equal(t, "bar", got["FOO"])
 }
 
@@ -205,12 +321,12 @@ func TestRunGitCheckIgnore_RealGit(t *testing.T) {
 
 func TestReadDotEnv_Missing(t *testing.T) {
 	dir := t.TempDir()
-	got := readDotEnv(filepath.Join(dir, ".env"))
+	got := readDotEnv(filepath.Join(dir, ".env"), nil)
 	require.Equal(t, 0, len(got))
 }
 
 func TestReadDotEnv_EmptyPath(t *testing.T) {
-	require.Equal(t, 0, len(readDotEnv("")))
+	require.Equal(t, 0, len(readDotEnv("", nil)))
 }
 
 func TestReadDotEnv_NoOpInjectWhenNoRefs(t *testing.T) {
@@ -223,7 +339,7 @@ func TestReadDotEnv_NoOpInjectWhenNoRefs(t *testing.T) {
 		t.Fatalf("opInject must not be called when no op:// references exist")
 		return "", nil
 	})
-	got := readDotEnv(path)
+	got := readDotEnv(path, nil)
 	require.Equal(t, "bar", got["FOO"])
 }
 
@@ -239,10 +355,13 @@ func TestReadDotEnv_OpInjectResolves(t *testing.T) {
 		calls++
 		return strings.ReplaceAll(in, "op://Personal/GitHub/token", "ghp_resolved"), nil
 	})
-	got := readDotEnv(path)
+	got := readDotEnv(path, nil)
 	require.Equal(t, 1, calls)
 	require.Equal(t, "ghp_resolved", got["GITHUB_TOKEN"])
 	require.Equal(t, "plain", got["OTHER"])
+
+	// resolved values are registered as secrets
+	require.Equal(t, "token: ***", redact.Registered("token: ghp_resolved"))
 }
 
 // TestCommand_DotEnvReachesChildEnv proves the full path: a .env file on disk
@@ -323,6 +442,62 @@ func TestReadDotEnv_OpInjectFailureFallsBackToRaw(t *testing.T) {
 	require.SetAndRestore(t, &opInject, func(string) (string, error) {
 		return "", fmt.Errorf("op binary not found")
 	})
-	got := readDotEnv(path)
+	got := readDotEnv(path, nil)
 	require.Equal(t, "op://x/y/z", got["GITHUB_TOKEN"])
 }
+
+func TestEnviron_IncludesDotEnv(t *testing.T) {
+	rootDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, ".env"), []byte("ENVIRON_TEST_KEY=from-dotenv\n"), 0o600))
+	require.SetAndRestore(t, &config.RootDir, rootDir)
+	require.SetAndRestore(t, &gitCheckIgnore, func(string) bool { return true })
+	require.SetAndRestore(t, &dotEnvOnce, &sync.Once{})
+	require.SetAndRestore(t, &dotEnvCache, map[string]string(nil))
+
+	require.Contains(t, Environ(), "ENVIRON_TEST_KEY=from-dotenv")
+}
+
+func TestLookupEnv(t *testing.T) {
+	rootDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, ".env"), []byte("LOOKUP_TEST_DOTENV=from-dotenv\nLOOKUP_TEST_BOTH=from-dotenv\n"), 0o600))
+	require.SetAndRestore(t, &config.RootDir, rootDir)
+	require.SetAndRestore(t, &gitCheckIgnore, func(string) bool { return true })
+	require.SetAndRestore(t, &dotEnvOnce, &sync.Once{})
+	require.SetAndRestore(t, &dotEnvCache, map[string]string(nil))
+	t.Setenv("LOOKUP_TEST_BOTH", "from-process")
+	// dropped by the default env policy, but still found
+	t.Setenv("GOLOOKUP_TEST", "go-value")
+
+	value, ok := LookupEnv("LOOKUP_TEST_DOTENV")
+	require.True(t, ok)
+	require.Equal(t, "from-dotenv", value)
+
+	value, _ = LookupEnv("LOOKUP_TEST_BOTH")
+	require.Equal(t, "from-process", value)
+
+	value, _ = LookupEnv("GOLOOKUP_TEST")
+	require.Equal(t, "go-value", value)
+
+	_, ok = LookupEnv("LOOKUP_TEST_UNSET")
+	require.False(t, ok)
+
+	// the skipped .env values are traced for commands only, not for each lookup
+	var traced []string
+	require.SetAndRestore(t, &log.Trace, func(format string, args ...any) {
+		traced = append(traced, fmt.Sprintf(format, args...))
+	})
+	lookup := EnvLookup()
+	value, _ = lookup("LOOKUP_TEST_BOTH")
+	require.Equal(t, "from-process", value)
+	_, _ = LookupEnv("LOOKUP_TEST_BOTH")
+	require.Equal(t, 0, len(traced))
+
+	_, err := Command("go", Args("version"), Quiet())
+	require.NoError(t, err)
+	require.Equal(t, 1, len(slices.DeleteFunc(traced, func(line string) bool {
+		return !strings.Contains(line, "LOOKUP_TEST_BOTH already set")
+	})))
+
+	// templates see the same values
+	require.Equal(t, "from-dotenv", template.Render(`{{ env "LOOKUP_TEST_DOTENV" }}`))
+}
@@ -0,0 +1,147 @@
+package run
+
+import (
+	"context"
+	"os/exec"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/anchore/go-make/color"
+)
+
+// EnvPolicy decides which variables of the go-make process environment are passed to commands.
+// Patterns are environment variable names, which may use * and ? wildcards, e.g. "GO*". Values
+// set with Env, Export and .env are always passed.
+type EnvPolicy struct {
+	// Keep patterns are always passed, even when matching Drop
+	Keep []string
+
+	// Drop patterns are not passed, unless matching Keep
+	Drop []string
+
+	// Clean passes only variables matching Keep, for a hermetic environment
+	Clean bool
+}
+
+// DefaultEnvPolicy returns the policy used unless another is set: it drops GO* and CGO_*
+// variables, which cause problems when the go-make process was itself started by `go run`,
+// such as GOROOT and GOEXPERIMENT, but keeps those that commonly configure builds in CI: module
+// proxy and privacy settings, GOFLAGS and CGO_ENABLED.
+func DefaultEnvPolicy() EnvPolicy {
+	return EnvPolicy{
+		Keep: []string{"GOFLAGS", "GOPROXY", "GOPRIVATE", "GONOPROXY", "GONOSUMDB", "GOSUMDB", "GOINSECURE", "CGO_ENABLED"},
+		Drop: []string{"GO*", "CGO_*"},
+	}
+}
+
+var (
+	envPolicyLock = &sync.Mutex{}
+	envPolicy     = DefaultEnvPolicy()
+)
+
+// SetEnvPolicy sets the environment policy for all commands executed afterward, until the
+// returned restore function is called. Tasks with an EnvPolicy set it while they run.
+//
+// Example:
+//
+//	run.SetEnvPolicy(run.EnvPolicy{Drop: []string{"GOROOT", "GOPATH"}})
+func SetEnvPolicy(policy EnvPolicy) (restore func()) {
+	envPolicyLock.Lock()
+	defer envPolicyLock.Unlock()
+
+	prev := envPolicy
+	envPolicy = policy
+	return func() {
+		envPolicyLock.Lock()
+		defer envPolicyLock.Unlock()
+		envPolicy = prev
+	}
+}
+
+func currentEnvPolicy() EnvPolicy {
+	envPolicyLock.Lock()
+	defer envPolicyLock.Unlock()
+	return envPolicy
+}
+
+// WithEnvPolicy uses the policy for this command instead of the policy set with SetEnvPolicy
+func WithEnvPolicy(policy EnvPolicy) Option {
+	return withEnvPolicy(func(p *EnvPolicy) {
+		*p = policy
+	})
+}
+
+// CleanEnv passes only the named variables of the go-make process environment to the command,
+// for a hermetic build, along with values set with Env, Export and .env
+//
+// Example:
+//
+//	Run(`go build ./...`, run.CleanEnv("PATH", "HOME", "GOPROXY"))
+func CleanEnv(keep ...string) Option {
+	return WithEnvPolicy(EnvPolicy{Keep: keep, Clean: true})
+}
+
+// KeepEnv passes variables matching the patterns to the command, in addition to the current policy
+func KeepEnv(patterns ...string) Option {
+	return withEnvPolicy(func(p *EnvPolicy) {
+		p.Keep = append(slices.Clone(p.Keep), patterns...)
+	})
+}
+
+// DropEnv does not pass variables matching the patterns to the command, in addition to the
+// current policy
+func DropEnv(patterns ...string) Option {
+	return withEnvPolicy(func(p *EnvPolicy) {
+		p.Drop = append(slices.Clone(p.Drop), patterns...)
+	})
+}
+
+func withEnvPolicy(update func(*EnvPolicy)) Option {
+	return func(ctx context.Context, _ *exec.Cmd) error {
+		cfg, _ := ctx.Value(runConfigKey{}).(*runConfig)
+		if cfg != nil {
+			if cfg.envPolicy == nil {
+				policy := currentEnvPolicy()
+				cfg.envPolicy = &policy
+			}
+			update(cfg.envPolicy)
+		}
+		return nil
+	}
+}
+
+// filter returns the entries of env passed by the policy, logging each decision with trace
+func (p EnvPolicy) filter(env []string, trace func(format string, args ...any)) []string {
+	var out []string
+	for _, entry := range env {
+		name, _, _ := strings.Cut(entry, "=")
+		if pattern := matchingPattern(p.Keep, name); pattern != "" {
+			trace(color.Grey("adding environment entry: %v (keep %v)", redactEnvEntry(entry), pattern))
+			out = append(out, entry)
+			continue
+		}
+		if p.Clean {
+			trace(color.Grey("dropped environment entry: %v (clean environment)", name))
+			continue
+		}
+		if pattern := matchingPattern(p.Drop, name); pattern != "" {
+			trace(color.Grey("dropped environment entry: %v (drop %v)", name, pattern))
+			continue
+		}
+		trace(color.Grey("adding environment entry: %v", redactEnvEntry(entry)))
+		out = append(out, entry)
+	}
+	return out
+}
+
+// matchingPattern returns the first pattern matching the name, or an empty string
+func matchingPattern(patterns []string, name string) string {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return pattern
+		}
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+package run
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/anchore/go-make/require"
+)
+
+func Test_EnvPolicy_filter(t *testing.T) {
+	env := []string{"PATH=/bin", "GOROOT=/go", "GOFLAGS=-mod=mod", "CGO_ENABLED=0", "CGO_CFLAGS=-O2", "API_TOKEN=secret-value"}
+
+	tests := []struct {
+		name     string
+		policy   EnvPolicy
+		expected []string
+		traces   []string
+	}{
+		{
+			name:     "default",
+			policy:   DefaultEnvPolicy(),
+			expected: []string{"PATH=/bin", "GOFLAGS=-mod=mod", "CGO_ENABLED=0", "API_TOKEN=secret-value"},
+			traces: []string{
+				"dropped environment entry: GOROOT (drop GO*)",
+				"adding environment entry: GOFLAGS=-mod=mod (keep GOFLAGS)",
+				"dropped environment entry: CGO_CFLAGS (drop CGO_*)",
+				"adding environment entry: API_TOKEN=***",
+			},
+		},
+		{
+			name:     "keep wins over drop",
+			policy:   EnvPolicy{Keep: []string{"GOROOT"}, Drop: []string{"GO*", "PATH"}},
+			expected: []string{"GOROOT=/go", "CGO_ENABLED=0", "CGO_CFLAGS=-O2", "API_TOKEN=secret-value"},
+		},
+		{
+			name:     "clean",
+			policy:   EnvPolicy{Keep: []string{"PATH", "CGO_*"}, Clean: true},
+			expected: []string{"PATH=/bin", "CGO_ENABLED=0", "CGO_CFLAGS=-O2"},
+			traces:   []string{"dropped environment entry: GOFLAGS (clean environment)"},
+		},
+		{
+			name:     "nothing",
+			policy:   EnvPolicy{},
+			expected: env,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var traces []string
+			trace := func(format string, args ...any) {
+				traces = append(traces, fmt.Sprintf(format, args...))
+			}
+			require.Equal(t, tt.expected, tt.policy.filter(env, trace))
+			for _, trace := range tt.traces {
+				require.Contains(t, strings.Join(traces, "\n"), trace)
+			}
+		})
+	}
+}
+
+func Test_EnvPolicy_Command(t *testing.T) {
+	testapp := buildTestApp(t)
+	t.Setenv("ENV_POLICY_TEST", "inherited")
+	t.Setenv("GO_POLICY_TEST", "inherited")
+	t.Setenv("GOFLAGS", "-mod=mod")
+
+	getenv := func(name string, opts ...Option) string {
+		out, err := Command(testapp, append([]Option{Args("env", name)}, opts...)...)
+		require.NoError(t, err)
+		return out
+	}
+
+	// the default policy
+	require.Equal(t, "inherited", getenv("ENV_POLICY_TEST"))
+	require.Equal(t, "", getenv("GO_POLICY_TEST"))
+	require.Equal(t, "-mod=mod", getenv("GOFLAGS"))
+
+	// per command
+	require.Equal(t, "inherited", getenv("GO_POLICY_TEST", KeepEnv("GO_POLICY_*")))
+	require.Equal(t, "", getenv("ENV_POLICY_TEST", DropEnv("ENV_POLICY_*")))
+	require.Equal(t, "", getenv("ENV_POLICY_TEST", CleanEnv("PATH")))
+	require.Equal(t, "inherited", getenv("ENV_POLICY_TEST", CleanEnv("PATH", "ENV_POLICY_TEST")))
+	require.Equal(t, "-mod=mod", getenv("GOFLAGS", WithEnvPolicy(EnvPolicy{})))
+
+	// explicit values are always passed
+	require.Equal(t, "explicit", getenv("ENV_POLICY_TEST", CleanEnv(), Env("ENV_POLICY_TEST", "explicit")))
+
+	// globally
+	restore := SetEnvPolicy(EnvPolicy{Drop: []string{"ENV_POLICY_*"}})
+	require.Equal(t, "", getenv("ENV_POLICY_TEST"))
+	require.Equal(t, "inherited", getenv("ENV_POLICY_TEST", KeepEnv("ENV_POLICY_TEST")))
+	restore()
+	require.Equal(t, "inherited", getenv("ENV_POLICY_TEST"))
+}
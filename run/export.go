@@ -0,0 +1,55 @@
+package run
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var (
+	exportsLock = &sync.Mutex{}
+	exports     = map[string]string{}
+)
+
+// Export sets an environment variable for every command executed afterward, until the returned
+// restore function is called. Unlike the inherited process environment, exported variables are
+// not subject to the EnvPolicy and take precedence over both the process environment and .env
+// values, e.g. to cross-compile for each entry of a task matrix:
+//
+//	defer run.Export("GOOS", "linux")()
+func Export(key, value string) (restore func()) {
+	exportsLock.Lock()
+	defer exportsLock.Unlock()
+
+	prev, hadPrev := exports[key]
+	exports[key] = value
+	return func() {
+		exportsLock.Lock()
+		defer exportsLock.Unlock()
+		if hadPrev {
+			exports[key] = prev
+		} else {
+			delete(exports, key)
+		}
+	}
+}
+
+// applyExports returns env with all exported variables set, replacing existing entries
+func applyExports(env []string) []string {
+	exportsLock.Lock()
+	defer exportsLock.Unlock()
+
+	if len(exports) == 0 {
+		return env
+	}
+	env = slices.DeleteFunc(env, func(entry string) bool {
+		name, _, _ := strings.Cut(entry, "=")
+		_, exported := exports[name]
+		return exported
+	})
+	for _, key := range slices.Sorted(maps.Keys(exports)) {
+		env = append(env, key+"="+exports[key])
+	}
+	return env
+}
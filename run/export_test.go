@@ -0,0 +1,49 @@
+package run
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/require"
+)
+
+func Test_applyExports(t *testing.T) {
+	env := []string{"PATH=/bin", "GOOS=darwin", "OTHER=value"}
+
+	// nothing exported leaves the environment untouched
+	require.EqualElements(t, env, applyExports(env))
+
+	restoreOS := Export("GOOS", "linux")
+	restoreArch := Export("GOARCH", "arm64")
+	require.EqualElements(t, []string{"PATH=/bin", "OTHER=value", "GOARCH=arm64", "GOOS=linux"}, applyExports(env))
+
+	// nested exports restore the previous value
+	restoreNested := Export("GOOS", "windows")
+	require.EqualElements(t, []string{"PATH=/bin", "OTHER=value", "GOARCH=arm64", "GOOS=windows"}, applyExports(env))
+	restoreNested()
+	require.EqualElements(t, []string{"PATH=/bin", "OTHER=value", "GOARCH=arm64", "GOOS=linux"}, applyExports(env))
+
+	restoreArch()
+	restoreOS()
+	require.EqualElements(t, env, applyExports(env))
+}
+
+func Test_ExportBypassesGoFilter(t *testing.T) {
+	testapp := filepath.Join(t.TempDir(), "testapp")
+	if config.Windows {
+		testapp += ".exe"
+	}
+	_, err := Command("go", Args("build", "-C", filepath.Join("testdata", "testapp"), "-o", testapp, "."))
+	require.NoError(t, err)
+
+	t.Setenv("GOOS_EXPORT_TEST", "from-process")
+	got, err := Command(testapp, Args("env", "GOOS_EXPORT_TEST"))
+	require.NoError(t, err)
+	require.Equal(t, "", got) // GO* variables are filtered by default
+
+	defer Export("GOOS_EXPORT_TEST", "exported")()
+	got, err = Command(testapp, Args("env", "GOOS_EXPORT_TEST"))
+	require.NoError(t, err)
+	require.Equal(t, "exported", got)
+}
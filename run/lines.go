@@ -0,0 +1,59 @@
+package run
+
+import (
+	"context"
+	"os/exec"
+
+	"github.com/anchore/go-make/stream"
+)
+
+// Stream names passed to OnLine callbacks
+const (
+	StdoutStream = "stdout"
+	StderrStream = "stderr"
+)
+
+// OnLine calls fn with each line of stdout and stderr as the command writes it, with the
+// stream it was written to: StdoutStream or StderrStream. Output is still captured and
+// written as it would be otherwise, so OnLine can follow the progress of long-running commands.
+// Lines from the two streams may arrive concurrently.
+//
+// Example:
+//
+//	Run(`go test -json ./...`, run.OnLine(func(stream, line string) {
+//	    if stream == run.StdoutStream && strings.Contains(line, `"Action":"fail"`) {
+//	        failures++
+//	    }
+//	}))
+func OnLine(fn func(stream, line string)) Option {
+	return func(ctx context.Context, _ *exec.Cmd) error {
+		cfg, _ := ctx.Value(runConfigKey{}).(*runConfig)
+		if cfg != nil {
+			cfg.onLine = append(cfg.onLine, fn)
+		}
+		return nil
+	}
+}
+
+// lineCallbacks tees the command's stdout and stderr to the OnLine callbacks, returning a func
+// that flushes any partial trailing lines once the command has completed
+func lineCallbacks(cmd *exec.Cmd, callbacks []func(stream, line string)) func() {
+	if len(callbacks) == 0 {
+		return func() {}
+	}
+	lines := func(streamName string) func(string) {
+		return func(line string) {
+			for _, fn := range callbacks {
+				fn(streamName, line)
+			}
+		}
+	}
+	stdoutLines := stream.Lines(lines(StdoutStream))
+	stderrLines := stream.Lines(lines(StderrStream))
+	cmd.Stdout = teeTo(cmd.Stdout, stdoutLines)
+	cmd.Stderr = teeTo(cmd.Stderr, stderrLines)
+	return func() {
+		_ = stdoutLines.Close()
+		_ = stderrLines.Close()
+	}
+}
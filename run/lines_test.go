@@ -0,0 +1,35 @@
+package run
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/anchore/go-make/require"
+)
+
+func Test_OnLine(t *testing.T) {
+	testapp := buildTestApp(t)
+
+	lock := sync.Mutex{}
+	lines := map[string][]string{}
+	onLine := OnLine(func(stream, line string) {
+		lock.Lock()
+		defer lock.Unlock()
+		lines[stream] = append(lines[stream], line)
+	})
+
+	out := bytes.Buffer{}
+	result, err := Command(testapp, Args("stdout", "one\r\ntwo\nthree", "stderr", "progress 1\nprogress 2\n"), Quiet(), onLine)
+	require.NoError(t, err)
+	require.Equal(t, "one\r\ntwo\nthree", result)
+	require.Equal(t, []string{"one", "two", "three"}, lines[StdoutStream])
+	require.Equal(t, []string{"progress 1", "progress 2"}, lines[StderrStream])
+
+	// lines are still written where redirected
+	clear(lines)
+	_, err = Command(testapp, Args("stdout", "redirected\n"), Stdout(&out), onLine)
+	require.NoError(t, err)
+	require.Equal(t, "redirected\n", out.String())
+	require.Equal(t, []string{"redirected"}, lines[StdoutStream])
+}
@@ -0,0 +1,145 @@
+package run
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+type pipeStage struct {
+	cmd  string
+	opts []Option
+}
+
+// Pipe sends the command's stdout to the stdin of cmd, run with its own opts such as Args, like
+// a shell pipeline but connected with OS pipes and without a shell. Multiple Pipe options form
+// a longer pipeline in order. Piped commands inherit the environment, directory, Quiet and
+// NoFail of the first command, and the output of the last command is returned or written to
+// the first command's Stdout. Stderr of every command is handled as usual. When any command
+// fails, the error and exit code are those of the last failing command; a command stopped by a
+// broken pipe, because a later command exited without reading all its input, is not a failure.
+//
+// Example:
+//
+//	run.Command("go", run.Args("list", "./..."),
+//	    run.Pipe("grep", run.Args("-v", "/test/")),
+//	    run.Pipe("xargs", run.Args("go", "vet")),
+//	)
+func Pipe(cmd string, opts ...Option) Option {
+	return func(ctx context.Context, _ *exec.Cmd) error {
+		cfg, _ := ctx.Value(runConfigKey{}).(*runConfig)
+		if cfg != nil {
+			cfg.pipe = append(cfg.pipe, pipeStage{cmd: cmd, opts: opts})
+		}
+		return nil
+	}
+}
+
+// pipeTo creates the commands piped to, connecting each command's stdout to the next command's
+// stdin, and the last command's stdout to this command's output
+func (e *execution) pipeTo() error {
+	out := e.c.Stdout
+	prev := e
+	for _, stage := range e.cfg.pipe {
+		next, err := newExecution(e.ctx, stage.cmd, append([]Option{e.inherit()}, stage.opts...))
+		if err != nil {
+			e.closePipes()
+			return err
+		}
+		r, w, err := os.Pipe()
+		if err != nil {
+			e.closePipes()
+			return err
+		}
+		e.pipes = append(e.pipes, r, w)
+		prev.setStdout(w)
+		next.c.Stdin = r
+		e.stages = append(e.stages, next)
+		prev = next
+	}
+	prev.setStdout(out)
+	return nil
+}
+
+// setStdout connects the command's stdout to w, along with its stderr when redirected to stdout
+func (e *execution) setStdout(w io.Writer) {
+	e.c.Stdout = w
+	if e.cfg.redirect == redirectStderrToStdout {
+		e.c.Stderr = w
+	}
+}
+
+// inherit returns an Option applying this command's environment, directory, Quiet and NoFail
+// to a piped command
+func (e *execution) inherit() Option {
+	return func(ctx context.Context, cmd *exec.Cmd) error {
+		cmd.Env = append(cmd.Env, e.explicitEnv...)
+		cmd.Dir = e.c.Dir
+		if cfg, _ := ctx.Value(runConfigKey{}).(*runConfig); cfg != nil {
+			cfg.noFail = e.cfg.noFail
+			cfg.envPolicy = e.cfg.envPolicy
+			cfg.piped = true
+		}
+		if e.cfg.quiet {
+			return Quiet()(ctx, cmd)
+		}
+		return nil
+	}
+}
+
+// startPipeline starts the piped commands, then closes this process's copies of the pipes, so
+// each command sees the end of its input when the previous command exits
+func (e *execution) startPipeline() {
+	for _, stage := range e.stages {
+		stage.start()
+	}
+	e.closePipes()
+}
+
+func (e *execution) closePipes() {
+	for _, f := range e.pipes {
+		_ = f.Close()
+	}
+	e.pipes = nil
+}
+
+// waitPipeline waits for all commands of the pipeline, returning the output of the last command
+// and the error of the last command to fail
+func (e *execution) waitPipeline() Result {
+	// the last command writes to this command's output, so wait for it before reading output
+	results := make([]Result, len(e.stages)+1)
+	for i, stage := range e.stages {
+		results[i+1] = stage.waitCommand()
+	}
+	results[0] = e.waitCommand()
+
+	result := Result{
+		Stdout:   results[0].Stdout,
+		Duration: results[0].Duration,
+	}
+	executions := append([]*execution{e}, e.stages...)
+	var stderr []string
+	for i, r := range results {
+		if r.Stderr != "" {
+			stderr = append(stderr, r.Stderr)
+		}
+		// like a shell, a command stopped writing to a later command that exited without
+		// reading all its input is not a failure
+		if i < len(results)-1 && brokenPipe(executions[i].c.ProcessState) {
+			continue
+		}
+		if r.Err != nil || r.ExitCode != 0 {
+			result.Err = r.Err
+			result.ExitCode = r.ExitCode
+		}
+	}
+	if e.timedOut.Load() {
+		// the whole pipeline was cancelled, report the timeout rather than a stage interrupted
+		result.Err = results[0].Err
+		result.ExitCode = results[0].ExitCode
+	}
+	result.Stderr = strings.Join(stderr, "\n")
+	return result
+}
@@ -0,0 +1,70 @@
+package run
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/require"
+)
+
+func Test_Pipe(t *testing.T) {
+	testapp := buildTestApp(t)
+
+	// output flows through each command to the result
+	out, err := Command(testapp, Args("stdout", "one\ntwo\n"),
+		Pipe(testapp, Args("cat", "-")),
+		Pipe(testapp, Args("cat", "-", "stdout", "three")),
+	)
+	require.NoError(t, err)
+	require.Equal(t, "one\ntwo\nthree", out)
+
+	// piped commands inherit the environment and output options of the first
+	buf := bytes.Buffer{}
+	out, err = Command(testapp, Args("stdout", "ignored"), Env("PIPE_TEST_VALUE", "inherited"), Stdout(&buf),
+		Pipe(testapp, Args("env", "PIPE_TEST_VALUE")),
+	)
+	require.NoError(t, err)
+	require.Equal(t, "", out)
+	require.Equal(t, "inherited", buf.String())
+}
+
+func Test_Pipe_failure(t *testing.T) {
+	testapp := buildTestApp(t)
+
+	result := CommandResult(testapp, Args("stdout", "data", "exit-code", "2"), Quiet(),
+		Pipe(testapp, Args("cat", "-", "stderr", "stage failed", "exit-code", "3")),
+		Pipe(testapp, Args("cat", "-")),
+	)
+	require.Error(t, result.Err)
+	require.Equal(t, 3, result.ExitCode)
+	require.Equal(t, "data", result.Stdout)
+	require.Contains(t, result.Stderr, "stage failed")
+	require.Contains(t, result.Err.Error(), "stage failed")
+
+	result = CommandResult(testapp, Args("exit-code", "4"), Quiet(), NoFail(), Pipe(testapp, Args("cat", "-")))
+	require.NoError(t, result.Err)
+	require.Equal(t, 4, result.ExitCode)
+}
+
+func Test_Pipe_brokenPipe(t *testing.T) {
+	if config.Windows {
+		t.Skip("no SIGPIPE on windows")
+	}
+	testapp := buildTestApp(t)
+
+	// the first command is killed writing more than the pipe buffers to a command not reading it
+	out, err := Command(testapp, Args("stdout", strings.Repeat("x", 100_000)), Quiet(),
+		Pipe(testapp, Args("stdout", "done")),
+	)
+	require.NoError(t, err)
+	require.Equal(t, "done", out)
+}
+
+func Test_Pipe_notFound(t *testing.T) {
+	testapp := buildTestApp(t)
+
+	_, err := Command(testapp, Args("stdout", "data"), Quiet(), Pipe("definitely-not-a-command-on-the-path"))
+	require.Error(t, err)
+}
@@ -0,0 +1,197 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/log"
+)
+
+// Process is a command started in the background with Start
+type Process struct {
+	name   string
+	cancel context.CancelFunc
+	done   chan struct{}
+	result Result
+
+	logsLock sync.Mutex
+	logs     []string
+}
+
+// Start starts a long-running command, such as a local registry, database or mock server, in
+// the background and returns without waiting for it to complete. Options are the same as for
+// Command; output lines are also kept for Logs and LogLine probes. Use Ready to wait until the
+// process is able to serve requests, and Stop to shut it down. Processes that are still running
+// are stopped when go-make exits, including when a task fails.
+//
+// Example:
+//
+//	registry := lang.Return(run.Start("docker", run.Args("run", "--rm", "-p", "5000:5000", "registry:2")))
+//	defer registry.Stop()
+//	lang.Throw(registry.Ready(30*time.Second, run.PortOpen(5000)))
+func Start(cmd string, opts ...Option) (*Process, error) {
+	ctx, cancel := context.WithCancel(Context())
+	p := &Process{
+		name:   cmd,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	e, err := newExecution(ctx, cmd, append(opts, OnLine(p.addLine)))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	e.start()
+	if e.startErr != nil {
+		cancel()
+		return nil, e.wait().Err
+	}
+
+	go func() {
+		defer close(p.done)
+		defer cancel()
+		p.result = e.wait()
+	}()
+	config.OnExit(p.Stop)
+	return p, nil
+}
+
+// WaitDelay sets how long to wait for a cancelled or stopped command to exit after being
+// interrupted, before it is killed
+func WaitDelay(d time.Duration) Option {
+	return func(_ context.Context, cmd *exec.Cmd) error {
+		cmd.WaitDelay = d
+		return nil
+	}
+}
+
+// Wait waits for the process to exit and returns its result
+func (p *Process) Wait() Result {
+	<-p.done
+	return p.result
+}
+
+// Stop interrupts the process and waits for it to exit, killing it if it hasn't exited after
+// the WaitDelay. Stopping a process that has already exited does nothing.
+func (p *Process) Stop() {
+	select {
+	case <-p.done:
+		return
+	default:
+	}
+	log.Debug("stopping %s", p.name)
+	p.cancel()
+	<-p.done
+}
+
+// Exited returns true when the process is no longer running
+func (p *Process) Exited() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Logs returns the output of the process so far, stdout and stderr combined
+func (p *Process) Logs() string {
+	return strings.Join(p.lines(), "\n")
+}
+
+func (p *Process) lines() []string {
+	p.logsLock.Lock()
+	defer p.logsLock.Unlock()
+	return append([]string(nil), p.logs...)
+}
+
+func (p *Process) addLine(_, line string) {
+	p.logsLock.Lock()
+	defer p.logsLock.Unlock()
+	p.logs = append(p.logs, line)
+}
+
+// Probe checks whether a process is ready, returning an error describing why not
+type Probe func(p *Process) error
+
+// probeInterval is the time between readiness checks
+var probeInterval = 100 * time.Millisecond
+
+// Ready waits until all probes succeed, returning an error with the process logs if the
+// process exits or the probes have not all succeeded within the timeout
+func (p *Process) Ready(timeout time.Duration, probes ...Probe) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		err := p.probe(probes)
+		if err == nil {
+			return nil
+		}
+		if p.Exited() {
+			return fmt.Errorf("%s exited before it was ready: %w\n%s", p.name, errors.Join(err, p.result.Err), p.Logs())
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s was not ready after %v: %w\n%s", p.name, timeout, err, p.Logs())
+		}
+		time.Sleep(probeInterval)
+	}
+}
+
+func (p *Process) probe(probes []Probe) error {
+	for _, probe := range probes {
+		if err := probe(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PortOpen is ready when a TCP connection to the port on localhost succeeds
+func PortOpen(port int) Probe {
+	address := net.JoinHostPort("localhost", strconv.Itoa(port))
+	return func(_ *Process) error {
+		conn, err := net.DialTimeout("tcp", address, time.Second)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
+
+// HTTPOK is ready when a GET request to the url responds with 200 OK
+func HTTPOK(url string) Probe {
+	client := http.Client{Timeout: time.Second}
+	return func(_ *Process) error {
+		rsp, err := client.Get(url) //nolint:noctx // bounded by the client timeout
+		if err != nil {
+			return err
+		}
+		_ = rsp.Body.Close()
+		if rsp.StatusCode != http.StatusOK {
+			return fmt.Errorf("GET %s: %s", url, rsp.Status)
+		}
+		return nil
+	}
+}
+
+// LogLine is ready when a line of the process output matches the regular expression
+func LogLine(pattern string) Probe {
+	re := regexp.MustCompile(pattern)
+	return func(p *Process) error {
+		for _, line := range p.lines() {
+			if re.MatchString(line) {
+				return nil
+			}
+		}
+		return fmt.Errorf("no output line matching %q", pattern)
+	}
+}
@@ -0,0 +1,74 @@
+package run
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/require"
+)
+
+func Test_Start(t *testing.T) {
+	testapp := buildTestApp(t)
+
+	p, err := Start(testapp, Args("listen", "0"), Quiet(), WaitDelay(time.Second))
+	require.NoError(t, err)
+	t.Cleanup(p.Stop)
+
+	require.NoError(t, p.Ready(10*time.Second, LogLine(`^listening on \d+$`)))
+	var port int
+	_, err = fmt.Sscanf(p.Logs(), "listening on %d", &port)
+	require.NoError(t, err)
+	require.NoError(t, p.Ready(10*time.Second, PortOpen(port), HTTPOK(fmt.Sprintf("http://localhost:%d/", port))))
+	require.False(t, p.Exited())
+
+	p.Stop()
+	require.True(t, p.Exited())
+	require.Error(t, PortOpen(port)(p))
+	// stopping again does nothing
+	p.Stop()
+}
+
+func Test_Start_exitedBeforeReady(t *testing.T) {
+	testapp := buildTestApp(t)
+
+	p, err := Start(testapp, Args("stdout", "starting\nbad config", "exit-code", "2"), Quiet())
+	require.NoError(t, err)
+
+	err = p.Ready(10*time.Second, LogLine("ready"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "exited before it was ready")
+	require.Contains(t, err.Error(), "bad config")
+	require.Equal(t, 2, p.Wait().ExitCode)
+}
+
+func Test_Start_notReady(t *testing.T) {
+	testapp := buildTestApp(t)
+
+	p, err := Start(testapp, Args("listen", "0"), Quiet(), WaitDelay(time.Second))
+	require.NoError(t, err)
+	t.Cleanup(p.Stop)
+
+	err = p.Ready(200*time.Millisecond, LogLine("never printed"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "was not ready after")
+	require.True(t, strings.Contains(err.Error(), `no output line matching "never printed"`))
+}
+
+func Test_Start_stoppedOnExit(t *testing.T) {
+	testapp := buildTestApp(t)
+
+	p, err := Start(testapp, Args("listen", "0"), Quiet(), WaitDelay(time.Second))
+	require.NoError(t, err)
+	require.NoError(t, p.Ready(10*time.Second, LogLine("listening on")))
+
+	config.DoExit()
+	require.True(t, p.Exited())
+}
+
+func Test_Start_notFound(t *testing.T) {
+	_, err := Start("definitely-not-a-command-on-the-path")
+	require.Error(t, err)
+}
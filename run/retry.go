@@ -0,0 +1,94 @@
+package run
+
+import (
+	"context"
+	"os/exec"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/anchore/go-make/log"
+)
+
+// RetryCondition decides whether a failed command should be retried, given its result
+type RetryCondition func(Result) bool
+
+type retryConfig struct {
+	attempts   int
+	backoff    time.Duration
+	conditions []RetryCondition
+}
+
+// Retry runs a failed command again, up to attempts times in total, waiting backoff before the
+// first retry and doubling the wait after each. Without conditions, any failure is retried;
+// otherwise only failures matching at least one condition, such as OnExitCode or OnStderr.
+// Commands are re-run with the same options, so a Stdin reader is not re-read from the start.
+//
+// Example:
+//
+//	Run(`npm install`, run.Retry(3, 2*time.Second, run.OnStderr(`ECONNRESET|ETIMEDOUT`)))
+func Retry(attempts int, backoff time.Duration, retryIf ...RetryCondition) Option {
+	return func(ctx context.Context, _ *exec.Cmd) error {
+		cfg, _ := ctx.Value(runConfigKey{}).(*runConfig)
+		if cfg != nil {
+			cfg.retry = &retryConfig{attempts: attempts, backoff: backoff, conditions: retryIf}
+		}
+		return nil
+	}
+}
+
+// OnExitCode retries commands exiting with any of the codes
+func OnExitCode(codes ...int) RetryCondition {
+	return func(r Result) bool {
+		return slices.Contains(codes, r.ExitCode)
+	}
+}
+
+// OnStderr retries commands with stderr matching the regular expression
+func OnStderr(pattern string) RetryCondition {
+	re := regexp.MustCompile(pattern)
+	return func(r Result) bool {
+		return re.MatchString(r.Stderr)
+	}
+}
+
+// Timeout cancels the command if it has not completed within d, failing with a "timed out
+// after d" error. Only this command is cancelled; with Retry, each attempt has its own timeout.
+func Timeout(d time.Duration) Option {
+	return func(ctx context.Context, _ *exec.Cmd) error {
+		cfg, _ := ctx.Value(runConfigKey{}).(*runConfig)
+		if cfg != nil {
+			cfg.timeout = d
+		}
+		return nil
+	}
+}
+
+// shouldRetry returns true when the result of the attempt failed in a way that is retried and
+// attempts remain
+func (r *retryConfig) shouldRetry(attempt int, result Result) bool {
+	if r == nil || attempt >= r.attempts || (result.Err == nil && result.ExitCode == 0) {
+		return false
+	}
+	if len(r.conditions) == 0 {
+		return true
+	}
+	for _, condition := range r.conditions {
+		if condition(result) {
+			return true
+		}
+	}
+	return false
+}
+
+// sleep waits before the next attempt, returning false if cancelled while waiting
+func (r *retryConfig) sleep(attempt int) bool {
+	wait := r.backoff << (attempt - 1)
+	log.Info("attempt %d/%d failed, retrying in %v", attempt, r.attempts, wait)
+	select {
+	case <-time.After(wait):
+		return true
+	case <-Context().Done():
+		return false
+	}
+}
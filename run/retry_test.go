@@ -0,0 +1,100 @@
+package run
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/anchore/go-make/require"
+)
+
+func Test_Retry(t *testing.T) {
+	testapp := buildTestApp(t)
+
+	tests := []struct {
+		name     string
+		args     []string
+		retryIf  []RetryCondition
+		attempts int32
+	}{
+		{
+			name:     "any failure",
+			args:     []string{"stdout", "attempt", "exit-code", "1"},
+			attempts: 3,
+		},
+		{
+			name:     "success is not retried",
+			args:     []string{"stdout", "attempt"},
+			attempts: 1,
+		},
+		{
+			name:     "matching exit code",
+			args:     []string{"stdout", "attempt", "exit-code", "75"},
+			retryIf:  []RetryCondition{OnExitCode(1, 75)},
+			attempts: 3,
+		},
+		{
+			name:     "other exit code",
+			args:     []string{"stdout", "attempt", "exit-code", "2"},
+			retryIf:  []RetryCondition{OnExitCode(1, 75)},
+			attempts: 1,
+		},
+		{
+			name:     "matching stderr",
+			args:     []string{"stdout", "attempt", "stderr", "npm ERR! network ECONNRESET", "exit-code", "1"},
+			retryIf:  []RetryCondition{OnExitCode(75), OnStderr(`ECONNRESET|ETIMEDOUT`)},
+			attempts: 3,
+		},
+		{
+			name:     "other stderr",
+			args:     []string{"stdout", "attempt", "stderr", "npm ERR! 404", "exit-code", "1"},
+			retryIf:  []RetryCondition{OnStderr(`ECONNRESET|ETIMEDOUT`)},
+			attempts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			result := CommandResult(testapp, Args(tt.args...), Quiet(), Retry(3, time.Millisecond, tt.retryIf...),
+				OnLine(func(stream, _ string) {
+					if stream == StdoutStream {
+						attempts.Add(1)
+					}
+				}))
+			require.Equal(t, tt.attempts, attempts.Load())
+			require.Equal(t, "attempt", result.Stdout)
+		})
+	}
+}
+
+func Test_retryConfig_shouldRetry(t *testing.T) {
+	var r *retryConfig
+	require.False(t, r.shouldRetry(1, Result{ExitCode: 1}))
+
+	r = &retryConfig{attempts: 2}
+	require.False(t, r.shouldRetry(1, Result{}))
+	require.True(t, r.shouldRetry(1, Result{Err: errors.New("failed")}))
+	require.True(t, r.shouldRetry(1, Result{ExitCode: 1}))
+	require.False(t, r.shouldRetry(2, Result{ExitCode: 1}))
+}
+
+func Test_Timeout(t *testing.T) {
+	testapp := buildTestApp(t)
+
+	start := time.Now()
+	result := CommandResult(testapp, Args("listen", "0"), Quiet(), Timeout(200*time.Millisecond), WaitDelay(time.Second))
+	require.Error(t, result.Err)
+	require.Contains(t, result.Err.Error(), "timed out after 200ms")
+	require.True(t, time.Since(start) < 5*time.Second)
+
+	// the timeout only applies to that command
+	_, err := Command(testapp, Args("stdout", "fast"), Timeout(10*time.Second))
+	require.NoError(t, err)
+
+	// and covers the whole pipeline
+	result = CommandResult(testapp, Args("stdout", "data"), Quiet(), Timeout(200*time.Millisecond), WaitDelay(time.Second),
+		Pipe(testapp, Args("cat", "-", "listen", "0")))
+	require.Error(t, result.Err)
+	require.Contains(t, result.Err.Error(), "timed out after 200ms")
+}
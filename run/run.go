@@ -9,14 +9,17 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/anchore/go-make/color"
 	"github.com/anchore/go-make/config"
-	"github.com/anchore/go-make/internal/redact"
+	"github.com/anchore/go-make/internal/actions"
 	"github.com/anchore/go-make/lang"
 	"github.com/anchore/go-make/log"
+	"github.com/anchore/go-make/redact"
 	"github.com/anchore/go-make/stream"
+	"github.com/anchore/go-make/template"
 )
 
 // Option is a functional option that modifies command execution behavior.
@@ -26,79 +29,177 @@ type Option func(context.Context, *exec.Cmd) error
 
 // Command runs a command, waits until completion, and returns stdout.
 // The first argument is the path to the binary and DOES NOT shell-split.
-// When not captured, stderr is output to os.Stderr and returned as part of the error text.
+// When not captured, stderr is output to log.Output and returned as part of the error text.
 func Command(cmd string, opts ...Option) (string, error) {
+	result := CommandResult(cmd, opts...)
+	return result.Stdout, result.Err
+}
+
+// Result is the outcome of a command run with CommandResult
+type Result struct {
+	// Stdout is the trimmed stdout, empty when redirected with the Stdout option
+	Stdout string
+	// Stderr is the trimmed stderr, captured in addition to wherever it is written
+	Stderr string
+	// ExitCode is the process exit code, 0 when the process could not be started
+	ExitCode int
+	// Duration is how long the command ran
+	Duration time.Duration
+	// Err is the error executing the command, nil on success or with NoFail
+	Err error
+}
+
+// CommandResult runs a command the same as Command, returning the captured output, exit code
+// and duration along with any error, rather than only stdout.
+//
+// Example:
+//
+//	result := run.CommandResult("go", run.Args("version"), run.Quiet())
+//	if result.ExitCode != 0 {
+//	    log.Warn("go version failed after %v: %s", result.Duration, result.Stderr)
+//	}
+func CommandResult(cmd string, opts ...Option) Result {
+	for attempt := 1; ; attempt++ {
+		e, err := newExecution(Context(), cmd, opts)
+		if err != nil {
+			return Result{Err: err}
+		}
+		e.start()
+		result := e.wait()
+		if !e.cfg.retry.shouldRetry(attempt, result) {
+			return result
+		}
+		if !e.cfg.retry.sleep(attempt) {
+			return result
+		}
+	}
+}
+
+// execution is a command prepared to run, with its output buffers and options
+type execution struct {
+	cmd         string
+	displayCmd  string
+	args        []string
+	opts        []Option
+	cfg         *runConfig
+	c           *exec.Cmd
+	stdout      *bytes.Buffer
+	stderr      *bytes.Buffer
+	stderrShown bool
+	scanner     *actions.Scanner
+	flush       func()
+	started     time.Time
+	startErr    error
+	explicitEnv []string
+	stages      []*execution
+	pipes       []*os.File
+	ctx         context.Context
+	cancel      context.CancelFunc
+	timer       *time.Timer
+	timedOut    atomic.Bool
+	afterWait   func()
+
+	stderrDisplay io.Writer
+	terminal      *terminal
+	terminalOut   io.Writer
+	terminalFlush func()
+}
+
+// newExecution creates the command, inheriting the environment and applying all options,
+// and logs the command line. The command runs until ctx is cancelled.
+func newExecution(ctx context.Context, cmd string, opts []Option) (*execution, error) {
+	e := &execution{
+		cmd:    cmd,
+		stdout: &bytes.Buffer{},
+		stderr: &bytes.Buffer{},
+		cfg:    &runConfig{},
+	}
+
 	// by default, only capture output without duplicating it to logs
-	opts = append([]Option{func(_ context.Context, cmd *exec.Cmd) error {
+	opts = append([]Option{func(ctx context.Context, cmd *exec.Cmd) error {
 		cmd.Stdout = io.Discard
-		cmd.Stderr = os.Stderr
+		cmd.Stderr = log.Writer(ctx)
 		cmd.Stdin = nil // do not attach stdin by default
 		return nil
 	}}, opts...)
 
-	stdout := bytes.Buffer{}
-	stderr := bytes.Buffer{}
-	opts = append(opts, func(_ context.Context, cmd *exec.Cmd) error {
+	opts = append(opts, func(ctx context.Context, cmd *exec.Cmd) error {
 		// if we are not outputting Stdout, capture and return it
 		if cmd.Stdout == io.Discard {
-			cmd.Stdout = &stdout
+			cmd.Stdout = e.stdout
+		}
+		if e.cfg.redirect == redirectStderrToStdout {
+			// the same writer for both, so the command writes them to a single pipe in order like
+			// a shell, locked in case they are wrapped separately later. stderr is in the output
+			// already, it doesn't need to be duplicated in errors
+			out := stream.Tee(cmd.Stdout)
+			cmd.Stdout = out
+			cmd.Stderr = out
+			e.stderrShown = true
+			e.stderrDisplay = out
+			return nil
 		}
 		// if the user isn't capturing stderr, we print to stderr by default and don't need to duplicate this in errors
-		if cmd.Stderr != os.Stderr {
-			cmd.Stderr = stream.Tee(cmd.Stderr, &stderr)
+		e.stderrShown = cmd.Stderr == log.Writer(ctx)
+		e.stderrDisplay = cmd.Stderr
+		cmd.Stderr = stream.Tee(cmd.Stderr, e.stderr)
+		if e.cfg.redirect == redirectStdoutToStderr {
+			cmd.Stdout = cmd.Stderr
 		}
 		return nil
 	})
+	e.opts = opts
 
-	// create the command, this will look it up based on path:
-	c := exec.CommandContext(Context(), cmd)
-
-	env := os.Environ()
-	var dropped []string
-	for i := range env {
-		nameValue := strings.SplitN(env[i], "=", 2)
-		if skipEnvVar(nameValue[0]) {
-			dropped = append(dropped, nameValue[0])
-			continue
-		}
-		log.Trace(color.Grey("adding environment entry: %v", redactEnvEntry(env[i])))
-		c.Env = append(c.Env, env[i])
-	}
+	// each command has its own context, so a Timeout only cancels this command
+	ctx, e.cancel = context.WithCancel(ctx)
+	e.ctx = ctx
 
-	for _, e := range dropped {
-		log.Trace(color.Grey("dropped environment entry: %v", e))
-	}
+	// create the command, this will look it up based on path:
+	c := exec.CommandContext(ctx, cmd)
+	e.c = c
 
-	// layer in <RootDir>/.env values (process env wins on conflict). intentionally
-	// NOT filtered by skipEnvVar — entries in .env are explicit user intent.
-	if dotEnv := loadDotEnv(); len(dotEnv) > 0 {
-		var skipped []string
-		c.Env, skipped = mergeDotEnv(c.Env, dotEnv)
-		for _, k := range skipped {
-			log.Trace(color.Grey("dotenv: %v already set in process env; skipping", k))
-		}
-	}
+	// WaitDelay specifies the time to wait after context cancellation (and the Cancel func
+	// being called) before force-killing the process.
+	c.WaitDelay = 11 * time.Second
 
-	cfg := runConfig{}
-	ctx := context.WithValue(Context(), runConfig{}, &cfg)
+	optCtx := context.WithValue(ctx, runConfigKey{}, e.cfg)
 
 	// finally, apply all the options to modify the command
 	for _, opt := range opts {
-		err := opt(ctx, c)
+		err := opt(optCtx, c)
 		if err != nil {
-			return "", err
+			e.cancel()
+			return nil, err
+		}
+	}
+
+	// values set by options, e.g. with Env, override the inherited environment
+	e.explicitEnv = c.Env
+	c.Env = append(e.inheritedEnv(), e.explicitEnv...)
+
+	e.displayCmd = cmd
+	if e.cfg.container != nil {
+		e.cfg.container.tty = e.cfg.tty && terminalSupported && !e.cfg.piped && len(e.cfg.pipe) == 0
+		if err := e.cfg.container.inContainer(c); err != nil {
+			e.cancel()
+			return nil, err
 		}
+		e.displayCmd = c.Path
 	}
 
 	// args is display-only (execution is driven by c); redact credential-looking
 	// values so a "--token=..." style argument never reaches the log line.
-	args := redact.Args(shortenedArgs(c.Args[1:])) // exec.Command sets the cmd to Args[0]
+	e.args = redact.Args(shortenedArgs(c.Args[1:])) // exec.Command sets the cmd to Args[0]
 
 	logFunc := log.Info
-	if cfg.quiet {
+	if e.cfg.quiet {
 		logFunc = log.Debug
 	}
-	logFunc("$ %v %v", displayPath(cmd), strings.Join(args, " "))
+	prompt := "$"
+	if e.cfg.piped {
+		prompt = "  |"
+	}
+	logFunc("%s %v %v", prompt, displayPath(e.displayCmd), strings.Join(e.args, " "))
 
 	// print out c.Env -- GOROOT vs GOBIN. Values of credential-looking entries
 	// (TOKEN/SECRET/PASSWORD/KEY/CREDENTIAL/...) are redacted so that turning
@@ -106,42 +207,180 @@ func Command(cmd string, opts ...Option) (string, error) {
 	// deploy keys to stderr.
 	log.Trace("ENV: %v", redactEnvList(c.Env))
 
-	// WaitDelay specifies the time to wait after context cancellation (and the Cancel func
-	// being called) before force-killing the process.
-	c.WaitDelay = 11 * time.Second
-	osExecOpts(c)
+	e.afterWait = osExecOpts(c)
+
+	var flushScanner func()
+	e.scanner, flushScanner = annotationScanner(c)
+	flushLines := lineCallbacks(c, e.cfg.onLine)
+	e.flush = func() {
+		flushScanner()
+		flushLines()
+	}
+
+	if len(e.cfg.pipe) > 0 {
+		if err := e.pipeTo(); err != nil {
+			e.cancel()
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// inheritedEnv returns the go-make process environment passed by the env policy, along with
+// .env values and exported values
+func (e *execution) inheritedEnv() []string {
+	policy := currentEnvPolicy()
+	if e.cfg.envPolicy != nil {
+		policy = *e.cfg.envPolicy
+	}
+	return environ(policy, log.Trace)
+}
 
-	// execute
-	err := c.Run()
+// Environ returns the environment commands receive, unless changed by options such as Env: the
+// go-make process environment filtered by the env policy, layered with the values of .env files
+// and exported values
+func Environ() []string {
+	return environ(currentEnvPolicy(), noTrace)
+}
+
+func init() {
+	// templates see the same environment as commands, including .env values
+	template.Globals["env"] = func(name string) string {
+		value, _ := LookupEnv(name)
+		return value
+	}
+}
+
+// LookupEnv returns the value of a variable in the go-make process environment layered with
+// the values of .env files and exported values, like Environ but regardless of the env policy,
+// and whether it is set
+func LookupEnv(name string) (string, bool) {
+	return EnvLookup()(name)
+}
+
+// EnvLookup returns a function looking up variables like LookupEnv, in the environment as it
+// is when EnvLookup is called, to look up many variables, such as when expanding a command
+// line, without merging the environment for each
+func EnvLookup() func(name string) (string, bool) {
+	env := environ(EnvPolicy{}, noTrace)
+	return func(name string) (string, bool) {
+		// later values override earlier ones, as for commands
+		for i := len(env) - 1; i >= 0; i-- {
+			if value, ok := strings.CutPrefix(env[i], name+"="); ok {
+				return value, true
+			}
+		}
+		return "", false
+	}
+}
+
+// environ returns the environment passed by the policy, logging the variables passed, dropped
+// and skipped with trace
+func environ(policy EnvPolicy, trace func(format string, args ...any)) []string {
+	env := policy.filter(os.Environ(), trace)
+
+	// layer in <RootDir>/.env values (process env wins on conflict). intentionally
+	// NOT filtered by the env policy — entries in .env are explicit user intent.
+	if dotEnv := loadDotEnv(); len(dotEnv) > 0 {
+		var skipped []string
+		env, skipped = mergeDotEnv(env, dotEnv)
+		for _, k := range skipped {
+			trace(color.Grey("dotenv: %v already set in process env; skipping", k))
+		}
+	}
+
+	// exported values, such as task matrix values, override everything inherited
+	return applyExports(env)
+}
+
+// noTrace discards trace logging, for environment lookups which would repeat it for every lookup
+func noTrace(string, ...any) {}
+
+// start starts the command, and any commands piped to, without waiting for it to complete
+func (e *execution) start() {
+	e.started = time.Now()
+	if e.cfg.timeout > 0 {
+		e.timer = time.AfterFunc(e.cfg.timeout, func() {
+			e.timedOut.Store(true)
+			e.cancel()
+		})
+	}
+	e.attachTerminal()
+	e.startErr = e.c.Start()
+	if e.terminal != nil {
+		e.terminal.forward(e.terminalOut)
+	}
+	if len(e.stages) > 0 {
+		e.startPipeline()
+	}
+}
+
+// wait waits for the command to complete and returns the result, with any error including
+// the command's output
+func (e *execution) wait() Result {
+	if len(e.stages) > 0 {
+		return e.waitPipeline()
+	}
+	return e.waitCommand()
+}
+
+func (e *execution) waitCommand() Result {
+	err := e.startErr
+	if err == nil {
+		err = e.c.Wait()
+		e.afterWait()
+	}
+	e.closeTerminal()
+	duration := time.Since(e.started)
+	if e.timer != nil {
+		e.timer.Stop()
+	}
+	e.cancel()
+	e.flush()
+	if err != nil && e.timedOut.Load() {
+		err = fmt.Errorf("timed out after %v", e.cfg.timeout)
+	}
 
 	exitCode := 0
-	if c.ProcessState != nil {
-		exitCode = c.ProcessState.ExitCode()
+	if e.c.ProcessState != nil {
+		exitCode = e.c.ProcessState.ExitCode()
 	}
+	auditExitCode := exitCode
+	if e.c.ProcessState == nil {
+		auditExitCode = -1
+	}
+	e.audit(auditExitCode, duration, e.startErr)
 	if err != nil {
 		fullStdOut := ""
-		if stdout.Len() > 0 {
-			fullStdOut = "\nSTDOUT:\n" + stdout.String()
+		if e.stdout.Len() > 0 {
+			fullStdOut = "\nSTDOUT:\n" + e.stdout.String()
 		}
-		if stderr.Len() > 0 {
-			fullStdOut += "\nSTDERR:\n" + stderr.String()
+		if e.stderr.Len() > 0 && !e.stderrShown {
+			fullStdOut += "\nSTDERR:\n" + e.stderr.String()
 		}
 		// scrub known secret shapes from the captured output before it is
 		// attached to the error: HandleErrors prints WithLog at info level, so a
 		// credential echoed by a failing command would otherwise leak even
 		// without debug logging enabled.
-		err = lang.NewStackTraceError(fmt.Errorf("error executing: '%s %s': %w", cmd, printArgs(opts), err)).
+		err = lang.NewStackTraceError(fmt.Errorf("error executing: '%s %s': %w", e.cmd, printArgs(e.opts), err)).
 			WithExitCode(exitCode).
-			WithLog(redact.Secrets(fullStdOut))
+			WithLog(redact.Secrets(fullStdOut)).
+			WithAnnotations(annotations(e.scanner)...)
 	}
 	if err != nil || exitCode > 0 {
-		if cfg.noFail {
-			log.Debug("error executing: '%v %v' exit code: %v: %v", displayPath(cmd), strings.Join(args, " "), exitCode, err)
+		if e.cfg.noFail {
+			log.Debug("error executing: '%v %v' exit code: %v: %v", displayPath(e.displayCmd), strings.Join(e.args, " "), exitCode, err)
 			err = nil
 		}
 	}
 
-	return strings.TrimSpace(stdout.String()), err
+	return Result{
+		Stdout:   strings.TrimSpace(e.stdout.String()),
+		Stderr:   strings.TrimSpace(e.stderr.String()),
+		ExitCode: exitCode,
+		Duration: duration,
+		Err:      err,
+	}
 }
 
 // Args appends args to the command
@@ -200,10 +439,10 @@ func Write(path string) Option {
 func Quiet() Option {
 	return func(ctx context.Context, cmd *exec.Cmd) error {
 		if !config.Debug {
-			if cmd.Stderr == os.Stderr {
+			if cmd.Stderr == log.Writer(ctx) {
 				cmd.Stderr = io.Discard
 			}
-			cfg, _ := ctx.Value(runConfig{}).(*runConfig)
+			cfg, _ := ctx.Value(runConfigKey{}).(*runConfig)
 			if cfg != nil {
 				cfg.quiet = true
 			}
@@ -224,7 +463,7 @@ func Quiet() Option {
 //	}
 func NoFail() Option {
 	return func(ctx context.Context, cmd *exec.Cmd) error {
-		cfg, _ := ctx.Value(runConfig{}).(*runConfig)
+		cfg, _ := ctx.Value(runConfigKey{}).(*runConfig)
 		if cfg != nil {
 			cfg.noFail = true
 		}
@@ -243,7 +482,7 @@ func Stdout(w io.Writer) Option {
 }
 
 // Stderr redirects the command's stderr to the provided writer. By default, stderr
-// is sent to os.Stderr. Use io.Discard to suppress error output.
+// is sent to log.Output (os.Stderr, labelled by task). Use io.Discard to suppress error output.
 func Stderr(w io.Writer) Option {
 	return func(_ context.Context, cmd *exec.Cmd) error {
 		cmd.Stderr = w
@@ -251,6 +490,34 @@ func Stderr(w io.Writer) Option {
 	}
 }
 
+// StderrToStdout writes the command's stderr to wherever its stdout is written, like 2>&1 in a
+// shell, so both are captured and returned together by default, in the order written. Stderr
+// is then not captured separately in Result.Stderr.
+func StderrToStdout() Option {
+	return redirect(redirectStderrToStdout)
+}
+
+// StdoutToStderr writes the command's stdout to wherever its stderr is written, like >&2 in a
+// shell, so it is shown in the log output and captured in Result.Stderr rather than returned
+func StdoutToStderr() Option {
+	return redirect(redirectStdoutToStderr)
+}
+
+const (
+	redirectStderrToStdout = "2>&1"
+	redirectStdoutToStderr = ">&2"
+)
+
+func redirect(r string) Option {
+	return func(ctx context.Context, _ *exec.Cmd) error {
+		cfg, _ := ctx.Value(runConfigKey{}).(*runConfig)
+		if cfg != nil {
+			cfg.redirect = r
+		}
+		return nil
+	}
+}
+
 // Stdin provides input to the command from the given reader. By default, stdin is
 // not connected (nil). Use this for commands that read from standard input.
 func Stdin(in io.Reader) Option {
@@ -261,8 +528,8 @@ func Stdin(in io.Reader) Option {
 }
 
 // Env adds an environment variable to the command's environment. Note that the command
-// inherits the current process environment by default (minus GO* and CGO_* variables
-// which are filtered to avoid conflicts).
+// inherits the current process environment by default, filtered by the EnvPolicy (see
+// DefaultEnvPolicy); variables set with Env are always passed.
 func Env(key, val string) Option {
 	return func(_ context.Context, cmd *exec.Cmd) error {
 		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, val))
@@ -322,16 +589,6 @@ func shortenedArgs(args []string) []string {
 	return args
 }
 
-func skipEnvVar(s string) bool {
-	// it causes problems to keep go environment variables in embedded go executions,
-	// removing them all seems to fix this up; a user needing a specific GO installation
-	// can specify environment variables to run commands using the Env
-	if strings.HasPrefix(s, "GO") || strings.HasPrefix(s, "CGO_") {
-		return true
-	}
-	return false
-}
-
 // redactEnvEntry returns a "NAME=VALUE" entry with the value replaced by
 // "***" when the name looks like a credential (see redact.IsSensitiveName).
 // Non-sensitive entries are returned unchanged. Used so trace logging can show
@@ -406,7 +663,19 @@ func printArgs(args []Option) string {
 	return strings.Join(argv, " ")
 }
 
+// runConfigKey is the context key for the *runConfig that options record settings in
+type runConfigKey struct{}
+
 type runConfig struct {
-	quiet  bool
-	noFail bool
+	quiet     bool
+	noFail    bool
+	onLine    []func(stream, line string)
+	container *containerConfig
+	pipe      []pipeStage
+	piped     bool
+	timeout   time.Duration
+	retry     *retryConfig
+	envPolicy *EnvPolicy
+	tty       bool
+	redirect  string
 }
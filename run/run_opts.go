@@ -3,11 +3,23 @@
 package run
 
 import (
+	"fmt"
+	"os"
 	"os/exec"
+	"strings"
 	"syscall"
+	"time"
+
+	"github.com/anchore/go-make/log"
 )
 
-func osExecOpts(c *exec.Cmd) {
+// processGroupPollInterval is how often to check whether a cancelled process group has exited
+const processGroupPollInterval = 50 * time.Millisecond
+
+// osExecOpts runs the command in its own process group, so cancellation reaches all processes
+// it spawns, such as test binaries run by go test. The returned function must be called after
+// the command has been waited for.
+func osExecOpts(c *exec.Cmd) (afterWait func()) {
 	// set pgid so any kill operations apply to spawned children
 	c.SysProcAttr = &syscall.SysProcAttr{
 		Pgid:    0,
@@ -15,10 +27,56 @@ func osExecOpts(c *exec.Cmd) {
 	}
 	// when the context is cancelled, send SIGINT to the entire process group for
 	// graceful shutdown instead of the default SIGKILL to just the child process.
+	var cancelled time.Time
 	c.Cancel = func() error {
 		if c.Process == nil {
 			return nil
 		}
+		cancelled = time.Now()
 		return syscall.Kill(-c.Process.Pid, syscall.SIGINT)
 	}
+	// exec kills only the child after the WaitDelay, so wait for the rest of the group to exit
+	// as well, escalating to SIGKILL for any processes remaining after the WaitDelay
+	return func() {
+		if cancelled.IsZero() || c.Process == nil {
+			return
+		}
+		pgid := c.Process.Pid
+		deadline := cancelled.Add(c.WaitDelay)
+		for processGroupExists(pgid) {
+			if time.Now().After(deadline) {
+				killProcessGroup(pgid)
+				return
+			}
+			time.Sleep(processGroupPollInterval)
+		}
+	}
+}
+
+// processGroupExists returns true when any process remains in the process group
+func processGroupExists(pgid int) bool {
+	return syscall.Kill(-pgid, 0) == nil
+}
+
+// killProcessGroup sends SIGKILL to all processes remaining in the process group, reporting them
+func killProcessGroup(pgid int) {
+	survivors := processGroupMembers(pgid)
+	if err := syscall.Kill(-pgid, syscall.SIGKILL); err != nil {
+		log.Debug("unable to kill process group %d: %v", pgid, err)
+		return
+	}
+	description := fmt.Sprintf("process group %d", pgid)
+	if len(survivors) > 0 {
+		description = strings.Join(survivors, ", ")
+	}
+	log.Warn("killed processes that outlived cancellation: %s", description)
+}
+
+// brokenPipe returns true when the process was killed by SIGPIPE, writing to a closed pipe
+func brokenPipe(state *os.ProcessState) bool {
+	if state == nil {
+		return false
+	}
+	status, ok := state.Sys().(syscall.WaitStatus)
+	return ok && status.Signaled() && status.Signal() == syscall.SIGPIPE
 }
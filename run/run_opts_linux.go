@@ -0,0 +1,32 @@
+package run
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// processGroupMembers returns the processes in the process group, as "pid (command)"
+func processGroupMembers(pgid int) []string {
+	stats, _ := filepath.Glob("/proc/[0-9]*/stat")
+	var out []string
+	for _, stat := range stats {
+		contents, err := os.ReadFile(stat)
+		if err != nil {
+			continue // exited while scanning
+		}
+		// format: pid (comm) state ppid pgrp ..., where comm may contain spaces and parentheses
+		s := string(contents)
+		end := strings.LastIndexByte(s, ')')
+		if end < 0 {
+			continue
+		}
+		fields := strings.Fields(s[end+1:])
+		if len(fields) < 3 || fields[2] != strconv.Itoa(pgid) {
+			continue
+		}
+		out = append(out, strings.TrimSpace(s[:end+1]))
+	}
+	return out
+}
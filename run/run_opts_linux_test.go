@@ -0,0 +1,65 @@
+package run
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anchore/go-make/require"
+)
+
+func Test_CancelKillsProcessGroup(t *testing.T) {
+	testapp := buildTestApp(t)
+
+	// the grandchild ignores the interrupt sent on timeout, so must be killed after the WaitDelay
+	result := CommandResult(testapp, Args("spawn", "1", "listen", "0"), Quiet(),
+		Timeout(300*time.Millisecond), WaitDelay(500*time.Millisecond))
+	require.Error(t, result.Err)
+
+	var child int
+	_, err := fmt.Sscanf(result.Stdout, "child %d", &child)
+	require.NoError(t, err)
+	// SIGKILL is delivered asynchronously
+	for deadline := time.Now().Add(2 * time.Second); processRunning(child) && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.False(t, processRunning(child))
+}
+
+func Test_processGroupMembers(t *testing.T) {
+	testapp := buildTestApp(t)
+
+	p, err := Start(testapp, Args("spawn", "1", "listen", "0"), Quiet(), WaitDelay(time.Second))
+	require.NoError(t, err)
+	defer p.Stop()
+	require.NoError(t, p.Ready(5*time.Second, LogLine("listening on")))
+
+	var child int
+	_, err = fmt.Sscanf(p.Logs(), "child %d", &child)
+	require.NoError(t, err)
+
+	// the group is the child's parent, the started command, and the child
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", child))
+	require.NoError(t, err)
+	fields := strings.Fields(string(stat)[strings.LastIndexByte(string(stat), ')')+1:])
+	pgid, err := strconv.Atoi(fields[2])
+	require.NoError(t, err)
+
+	members := processGroupMembers(pgid)
+	require.Equal(t, 2, len(members))
+	require.Contains(t, strings.Join(members, " "), fmt.Sprintf("%d (testapp)", child))
+}
+
+// processRunning returns true when the process exists and is not a zombie awaiting its parent
+func processRunning(pid int) bool {
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return false
+	}
+	s := string(stat)
+	fields := strings.Fields(s[strings.LastIndexByte(s, ')')+1:])
+	return len(fields) > 0 && fields[0] != "Z"
+}
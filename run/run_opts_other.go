@@ -0,0 +1,8 @@
+//go:build !windows && !linux
+
+package run
+
+// processGroupMembers returns the processes in the process group, which is unknown on this platform
+func processGroupMembers(_ int) []string {
+	return nil
+}
@@ -3,6 +3,7 @@ package run
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -72,13 +73,7 @@ func Test_Command(t *testing.T) {
 	buf1 := bytes.Buffer{}
 	buf2 := bytes.Buffer{}
 
-	tmpDir := t.TempDir()
-	testapp := filepath.Join(tmpDir, "testapp")
-	if config.Windows {
-		testapp += ".exe"
-	}
-	_, err := Command("go", Args("build", "-C", filepath.Join("testdata", "testapp"), "-o", testapp, "."))
-	require.NoError(t, err)
+	testapp := buildTestApp(t)
 
 	tests := []struct {
 		name     string
@@ -142,3 +137,95 @@ func Test_Command(t *testing.T) {
 		})
 	}
 }
+
+func Test_CommandResult(t *testing.T) {
+	testapp := buildTestApp(t)
+
+	result := CommandResult(testapp, Args("stdout", " out \n", "stderr", "err", "exit-code", "3"), Quiet())
+	require.Error(t, result.Err)
+	require.Equal(t, "out", result.Stdout)
+	require.Equal(t, "err", result.Stderr)
+	require.Equal(t, 3, result.ExitCode)
+	require.True(t, result.Duration > 0)
+
+	result = CommandResult(testapp, Args("stderr", "err", "exit-code", "3"), Quiet(), NoFail())
+	require.NoError(t, result.Err)
+	require.Equal(t, 3, result.ExitCode)
+
+	// stderr is captured even when written to the log
+	logged := bytes.Buffer{}
+	require.SetAndRestore(t, &log.Output, io.Writer(&logged))
+	result = CommandResult(testapp, Args("stderr", "shown"))
+	require.NoError(t, result.Err)
+	require.Equal(t, "shown", result.Stderr)
+	require.Contains(t, logged.String(), "shown")
+}
+
+func Test_StderrToStdout(t *testing.T) {
+	testapp := buildTestApp(t)
+
+	result := CommandResult(testapp, Args("stdout", "out\n", "stderr", "err\n"), StderrToStdout())
+	require.NoError(t, result.Err)
+	require.Equal(t, "out\nerr", result.Stdout)
+	require.Equal(t, "", result.Stderr)
+
+	// written to the same writer when stdout is redirected
+	out := bytes.Buffer{}
+	result = CommandResult(testapp, Args("stdout", "out\n", "stderr", "err\n"), StderrToStdout(), Stdout(&out))
+	require.NoError(t, result.Err)
+	require.Equal(t, "out\nerr\n", out.String())
+
+	// and to the next command of a pipeline
+	result = CommandResult(testapp, Args("stdout", "out\n", "stderr", "err\n"), StderrToStdout(), Pipe(testapp, Args("cat", "")))
+	require.NoError(t, result.Err)
+	require.Equal(t, "out\nerr", result.Stdout)
+}
+
+func Test_StdoutToStderr(t *testing.T) {
+	testapp := buildTestApp(t)
+
+	logged := bytes.Buffer{}
+	require.SetAndRestore(t, &log.Output, io.Writer(&logged))
+
+	result := CommandResult(testapp, Args("stdout", "out\n"), StdoutToStderr())
+	require.NoError(t, result.Err)
+	require.Equal(t, "", result.Stdout)
+	require.Equal(t, "out", result.Stderr)
+	require.Contains(t, logged.String(), "out")
+}
+
+func Test_SetOutput(t *testing.T) {
+	testapp := buildTestApp(t)
+
+	logged := bytes.Buffer{}
+	require.SetAndRestore(t, &log.Output, io.Writer(&logged))
+
+	taskOutput := bytes.Buffer{}
+	restore := SetOutput(&taskOutput)
+	log.Info("task log")
+	// started while the task runs, the command keeps writing to the task output
+	e, err := newExecution(Context(), testapp, []Option{Args("stderr", "task stderr")})
+	require.NoError(t, err)
+	restore()
+
+	log.Info("after the task")
+	e.start()
+	require.NoError(t, e.wait().Err)
+
+	require.Contains(t, taskOutput.String(), "task log")
+	require.Contains(t, taskOutput.String(), "task stderr")
+	require.Equal(t, "after the task\n", logged.String())
+}
+
+// buildTestApp builds testdata/testapp, which writes its arguments in pairs: stdout <value>,
+// stderr <value>, env <name>, stdin, exit-code <code>
+func buildTestApp(t *testing.T) string {
+	t.Helper()
+	testapp := filepath.Join(t.TempDir(), "testapp")
+	if config.Windows {
+		testapp += ".exe"
+	}
+	_, err := Command("go", Args("build", "-C", filepath.Join("testdata", "testapp"), "-o", testapp, "."))
+	require.NoError(t, err)
+	return testapp
+}
@@ -0,0 +1,142 @@
+package run
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/log"
+	"github.com/anchore/go-make/redact"
+	"github.com/anchore/go-make/shell"
+	"github.com/anchore/go-make/template"
+)
+
+// SecretResolver returns the secret a reference in a .env file refers to. It receives the
+// reference without its scheme, e.g. "path/to/token" for pass://path/to/token.
+type SecretResolver func(ref string) (string, error)
+
+var (
+	secretResolversLock = &sync.Mutex{}
+	secretResolvers     = map[string]SecretResolver{}
+
+	// secretCommand is overridable in tests. Returns the stdout of the command. Uses stdlib
+	// os/exec directly (not run.Command) to avoid recursing back into dotenv loading.
+	secretCommand = runSecretCommand
+)
+
+// RegisterSecretResolver resolves .env values that are references of the form <scheme>://<ref>
+// with the resolver, replacing any resolver registered for the scheme. Resolved values are
+// masked in output. Only op:// references are resolved by default, with op inject; other schemes
+// are left alone until a resolver is registered, so ordinary values such as file:// URLs are not
+// rewritten. FileSecret, EnvSecret, CommandSecret, SopsSecret and PassSecret may be registered
+// for common secret stores.
+//
+// Register resolvers in .make/main.go before running any tasks. Resolvers run while .env files
+// are loading, so must not run commands with Run or Command, which wait for loading to complete.
+//
+// Example:
+//
+//	run.RegisterSecretResolver("sops", run.SopsSecret)
+//	run.RegisterSecretResolver("vault", func(ref string) (string, error) {
+//	    path, field, _ := strings.Cut(ref, "#")
+//	    return vaultClient.Read(path, field)
+//	})
+func RegisterSecretResolver(scheme string, resolver SecretResolver) {
+	secretResolversLock.Lock()
+	defer secretResolversLock.Unlock()
+	secretResolvers[scheme] = resolver
+}
+
+// resolveSecret resolves value when it is a reference with a registered scheme, registering the
+// resolved value for redaction. Other values, including unregistered schemes such as https://,
+// are returned unchanged, as are references failing to resolve, with a warning.
+func resolveSecret(key, value string) string {
+	scheme, ref, ok := strings.Cut(value, "://")
+	if !ok {
+		return value
+	}
+	secretResolversLock.Lock()
+	resolver := secretResolvers[scheme]
+	secretResolversLock.Unlock()
+	if resolver == nil {
+		return value
+	}
+	resolved, err := resolver(ref)
+	if err != nil {
+		log.Warn("dotenv: cannot resolve %s for %s: %v; using raw value", scheme+"://", key, err)
+		return value
+	}
+	redact.Register(resolved)
+	return resolved
+}
+
+// FileSecret reads the file at ref, relative to the project root unless absolute, without trailing newlines
+func FileSecret(ref string) (string, error) {
+	path := ref
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(template.Render(config.RootDir), path)
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(contents), "\r\n"), nil
+}
+
+// EnvSecret returns the variable ref of the process environment, failing when it is not set
+func EnvSecret(ref string) (string, error) {
+	value, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", fmt.Errorf("%s is not set", ref)
+	}
+	return value, nil
+}
+
+// CommandSecret returns the output of the command line ref, run in the project root
+func CommandSecret(ref string) (string, error) {
+	args := shell.Split(ref)
+	if len(args) == 0 {
+		return "", fmt.Errorf("no command")
+	}
+	return secretCommand(args[0], args[1:]...)
+}
+
+// SopsSecret decrypts a file with sops, extracting the value at the dotted key path following #,
+// e.g. secrets.enc.yaml#db.password, or returning the whole file without one
+func SopsSecret(ref string) (string, error) {
+	path, keyPath, _ := strings.Cut(ref, "#")
+	args := []string{"--decrypt"}
+	if keyPath != "" {
+		extract := ""
+		for _, key := range strings.Split(keyPath, ".") {
+			extract += fmt.Sprintf("[%q]", key)
+		}
+		args = append(args, "--extract", extract)
+	}
+	return secretCommand("sops", append(args, path)...)
+}
+
+// PassSecret returns the first line of a pass secret, which by convention holds the password
+func PassSecret(ref string) (string, error) {
+	out, err := secretCommand("pass", "show", ref)
+	if err != nil {
+		return "", err
+	}
+	first, _, _ := strings.Cut(out, "\n")
+	return first, nil
+}
+
+func runSecretCommand(name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = template.Render(config.RootDir)
+	cmd.Stderr = os.Stderr // surface prompts and errors directly
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
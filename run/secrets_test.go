@@ -0,0 +1,99 @@
+package run
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/redact"
+	"github.com/anchore/go-make/require"
+)
+
+func Test_resolveSecret(t *testing.T) {
+	rootDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, "token.txt"), []byte("file-secret-value\n"), 0o600))
+	require.SetAndRestore(t, &config.RootDir, rootDir)
+	t.Setenv("SECRETS_TEST_SOURCE", "env-secret-value")
+	require.SetAndRestore(t, &secretResolvers, map[string]SecretResolver{
+		"file": FileSecret,
+		"env":  EnvSecret,
+		"cmd":  CommandSecret,
+		"sops": SopsSecret,
+		"pass": PassSecret,
+	})
+
+	var commands []string
+	require.SetAndRestore(t, &secretCommand, func(name string, args ...string) (string, error) {
+		commands = append(commands, strings.Join(append([]string{name}, args...), " "))
+		switch name {
+		case "pass":
+			return "pass-secret-value\nuser: me", nil
+		case "fail":
+			return "", fmt.Errorf("exit status 1")
+		}
+		return name + "-secret-value", nil
+	})
+
+	tests := []struct {
+		value    string
+		expected string
+		command  string
+	}{
+		{value: "plain", expected: "plain"},
+		{value: "https://example.com/path", expected: "https://example.com/path"},
+		{value: "op://vault/item/field", expected: "op://vault/item/field"},
+		{value: "file://token.txt", expected: "file-secret-value"},
+		{value: "file://" + filepath.Join(rootDir, "token.txt"), expected: "file-secret-value"},
+		{value: "file://missing.txt", expected: "file://missing.txt"},
+		{value: "env://SECRETS_TEST_SOURCE", expected: "env-secret-value"},
+		{value: "env://SECRETS_TEST_UNSET", expected: "env://SECRETS_TEST_UNSET"},
+		{value: "cmd://vault read -field=token 'secret/my app'", expected: "vault-secret-value", command: "vault read -field=token secret/my app"},
+		{value: "cmd://fail now", expected: "cmd://fail now", command: "fail now"},
+		{value: "sops://secrets.enc.yaml#db.password", expected: "sops-secret-value", command: `sops --decrypt --extract ["db"]["password"] secrets.enc.yaml`},
+		{value: "sops://secrets.enc.env", expected: "sops-secret-value", command: "sops --decrypt secrets.enc.env"},
+		{value: "pass://team/deploy-token", expected: "pass-secret-value", command: "pass show team/deploy-token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			commands = nil
+			require.Equal(t, tt.expected, resolveSecret("KEY", tt.value))
+			if tt.command != "" {
+				require.Equal(t, []string{tt.command}, commands)
+			} else {
+				require.Equal(t, 0, len(commands))
+			}
+		})
+	}
+
+	// resolved values are masked in output
+	require.Equal(t, redact.Mask, redact.Registered("env-secret-value"))
+}
+
+func Test_resolveSecret_notRegistered(t *testing.T) {
+	rootDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, "token.txt"), []byte("file-secret-value\n"), 0o600))
+	require.SetAndRestore(t, &config.RootDir, rootDir)
+	t.Setenv("SECRETS_TEST_SOURCE", "env-secret-value")
+
+	// built-in resolvers are opt-in, ordinary values are not rewritten by default
+	for _, value := range []string{"file://token.txt", "env://SECRETS_TEST_SOURCE", "cmd://echo hi", "sops://x.enc.yaml", "pass://x"} {
+		require.Equal(t, value, resolveSecret("KEY", value))
+	}
+}
+
+func Test_RegisterSecretResolver(t *testing.T) {
+	require.SetAndRestore(t, &secretResolvers, map[string]SecretResolver{})
+	RegisterSecretResolver("vault", func(ref string) (string, error) {
+		return "resolved-" + ref, nil
+	})
+
+	got := parseDotEnvWith("TOKEN=vault://deploy\nHEADER=\"Bearer ${TOKEN}\"\nOTHER=file://x", nil, resolveSecret)
+	require.Equal(t, "resolved-deploy", got["TOKEN"])
+	// later entries interpolate the resolved value
+	require.Equal(t, "Bearer resolved-deploy", got["HEADER"])
+	// schemes without a resolver are left alone
+	require.Equal(t, "file://x", got["OTHER"])
+}
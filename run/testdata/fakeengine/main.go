@@ -0,0 +1,20 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// a stand-in for a container engine: prints each argument on its own line, followed by
+// NAME=value for each environment variable passed with -e, as the container would receive it
+func main() {
+	args := os.Args[1:]
+	for _, arg := range args {
+		fmt.Println(arg)
+	}
+	for i, arg := range args {
+		if arg == "-e" && i+1 < len(args) {
+			fmt.Printf("%s=%s\n", args[i+1], os.Getenv(args[i+1]))
+		}
+	}
+}
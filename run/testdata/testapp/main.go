@@ -1,8 +1,13 @@
 package main
 
 import (
+	"fmt"
 	"io"
+	"net"
+	"net/http"
 	"os"
+	"os/exec"
+	"os/signal"
 	"strconv"
 )
 
@@ -28,10 +33,37 @@ func main() {
 				value += string(buf[0])
 			}
 			g(os.Stderr.WriteString(value))
+		case "cat":
+			// copy stdin to stdout, the value is ignored
+			g(io.Copy(os.Stdout, os.Stdin))
 		case "env":
 			g(os.Stdout.WriteString(os.Getenv(os.Args[i+1])))
 		case "exit-code":
 			exit = g(strconv.Atoi(os.Args[i+1]))
+		case "isatty":
+			// report whether stdout is a terminal, the value is ignored
+			if stat := g(os.Stdout.Stat()); stat.Mode()&os.ModeCharDevice != 0 {
+				g(os.Stdout.WriteString("stdout is a terminal\n"))
+			} else {
+				g(os.Stdout.WriteString("stdout is not a terminal\n"))
+			}
+		case "ignore-interrupt":
+			// keep running when interrupted, the value is ignored
+			signal.Ignore(os.Interrupt)
+		case "spawn":
+			// start a child ignoring interrupts that runs until killed, printing its pid
+			child := exec.Command(os.Args[0], "ignore-interrupt", "1", "listen", "0")
+			if err := child.Start(); err != nil {
+				panic(err)
+			}
+			g(fmt.Printf("child %d\n", child.Process.Pid))
+		case "listen":
+			// serve HTTP on the port, 0 for any, until interrupted
+			listener := g(net.Listen("tcp", "localhost:"+os.Args[i+1]))
+			g(fmt.Printf("listening on %d\n", listener.Addr().(*net.TCPAddr).Port))
+			panic(http.Serve(listener, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})))
 		}
 		i++
 	}
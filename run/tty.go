@@ -0,0 +1,112 @@
+package run
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+
+	"golang.org/x/term"
+
+	"github.com/anchore/go-make/log"
+)
+
+// TTY runs the command under a pseudo-terminal, so tools such as golangci-lint, go test and
+// goreleaser keep the colors and progress output they only write to terminals. The terminal
+// combines stdout and stderr: the output is shown on the command's Stderr (log output by
+// default, hidden by Quiet), with colors when go-make's own stderr is a terminal, and written to
+// the command's Stdout with terminal escape sequences removed, so it is still captured and
+// returned, and included in errors. Supported on Linux; elsewhere, and for commands piped to
+// another command, the command runs without a terminal.
+//
+// Example:
+//
+//	Run(`golangci-lint run`, run.TTY())
+func TTY() Option {
+	return func(ctx context.Context, _ *exec.Cmd) error {
+		cfg, _ := ctx.Value(runConfigKey{}).(*runConfig)
+		if cfg != nil {
+			cfg.tty = true
+		}
+		return nil
+	}
+}
+
+// attachTerminal connects the command to a pseudo-terminal when requested and supported
+func (e *execution) attachTerminal() {
+	if !e.cfg.tty || e.cfg.piped || len(e.stages) > 0 {
+		return
+	}
+	t, err := openTerminal()
+	if err != nil {
+		log.Debug("running %s without a terminal: %v", displayPath(e.displayCmd), err)
+		return
+	}
+
+	captured := &ansiStripper{w: e.c.Stdout}
+	display := e.stderrDisplay
+	flush := captured.flush
+	if !stderrIsTerminal() {
+		stripped := &ansiStripper{w: display}
+		display = stripped
+		flush = func() {
+			captured.flush()
+			stripped.flush()
+		}
+	}
+
+	t.attach(e.c)
+	e.terminal = t
+	e.terminalOut = io.MultiWriter(display, captured)
+	e.terminalFlush = flush
+}
+
+// closeTerminal waits for the remaining terminal output after the command exits and closes it
+func (e *execution) closeTerminal() {
+	if e.terminal == nil {
+		return
+	}
+	e.terminal.close()
+	e.terminalFlush()
+}
+
+func stderrIsTerminal() bool {
+	return term.IsTerminal(int(os.Stderr.Fd())) //nolint:gosec // G115: file descriptors fit in an int
+}
+
+// ansiEscape matches terminal control sequences: CSI sequences such as colors and cursor
+// movement, OSC sequences such as window titles and hyperlinks, and two-character escapes
+var ansiEscape = regexp.MustCompile(`\x1b(?:\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(?:\x07|\x1b\\)|[@-Z\\-_])`)
+
+// partialEscapeLimit is the longest incomplete escape sequence held back for the next write
+const partialEscapeLimit = 256
+
+// ansiStripper writes to w with terminal escape sequences removed, holding back an incomplete
+// sequence at the end of a write until the next
+type ansiStripper struct {
+	w       io.Writer
+	pending []byte
+}
+
+func (s *ansiStripper) Write(p []byte) (int, error) {
+	data := append(s.pending, p...)
+	s.pending = nil
+	if i := bytes.LastIndexByte(data, 0x1b); i >= 0 && len(data)-i < partialEscapeLimit {
+		if loc := ansiEscape.FindIndex(data[i:]); loc == nil || loc[0] != 0 {
+			s.pending = bytes.Clone(data[i:])
+			data = data[:i]
+		}
+	}
+	_, err := s.w.Write(ansiEscape.ReplaceAll(data, nil))
+	return len(p), err
+}
+
+// flush writes any held back data, which was not an escape sequence after all
+func (s *ansiStripper) flush() {
+	if len(s.pending) > 0 {
+		_, _ = s.w.Write(s.pending)
+		s.pending = nil
+	}
+}
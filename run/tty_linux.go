@@ -0,0 +1,130 @@
+package run
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// terminalSupported is true on platforms where TTY runs commands under a pseudo-terminal
+const terminalSupported = true
+
+// terminalDrainTimeout is how long to keep reading output after the command exits, which
+// processes it started that are still running may continue to write
+const terminalDrainTimeout = 2 * time.Second
+
+// terminal is a pseudo-terminal the command is attached to
+type terminal struct {
+	master *os.File
+	slave  *os.File
+	done   chan struct{}
+}
+
+// openTerminal opens a new pseudo-terminal, sized like go-make's terminal if it has one
+func openTerminal() (t *terminal, err error) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = master.Close()
+		}
+	}()
+
+	// use the raw connection rather than Fd, which makes reads blocking so Close won't interrupt them
+	var ptyNumber uint32
+	if err = control(master, func(fd int) error {
+		if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+			return fmt.Errorf("unlocking terminal: %w", err)
+		}
+		ptyNumber, err = unix.IoctlGetUint32(fd, unix.TIOCGPTN)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	slave, err := os.OpenFile(fmt.Sprintf("/dev/pts/%d", ptyNumber), os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		return nil, err
+	}
+	err = control(slave, func(fd int) error {
+		// keep newlines as written, rather than translating them to \r\n
+		termios, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+		if err != nil {
+			return err
+		}
+		termios.Oflag &^= unix.ONLCR
+		if err = unix.IoctlSetTermios(fd, unix.TCSETS, termios); err != nil {
+			return err
+		}
+		if size, err := unix.IoctlGetWinsize(int(os.Stderr.Fd()), unix.TIOCGWINSZ); err == nil { //nolint:gosec // G115: file descriptors fit in an int
+			return unix.IoctlSetWinsize(fd, unix.TIOCSWINSZ, size)
+		}
+		return nil
+	})
+	if err != nil {
+		_ = slave.Close()
+		return nil, err
+	}
+	return &terminal{master: master, slave: slave}, nil
+}
+
+func control(f *os.File, fn func(fd int) error) error {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var fnErr error
+	if err = conn.Control(func(fd uintptr) {
+		fnErr = fn(int(fd)) //nolint:gosec // G115: file descriptors fit in an int
+	}); err != nil {
+		return err
+	}
+	return fnErr
+}
+
+// attach connects the command's output to the terminal, making it the controlling terminal
+func (t *terminal) attach(c *exec.Cmd) {
+	c.Stdout = t.slave
+	c.Stderr = t.slave
+	if c.SysProcAttr == nil {
+		c.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	// a new session has a new process group, led by the command as Setpgid would
+	c.SysProcAttr.Setpgid = false
+	c.SysProcAttr.Setsid = true
+	c.SysProcAttr.Setctty = true
+	c.SysProcAttr.Ctty = 1 // stdout, in the child
+}
+
+// forward copies the terminal output to w, after the command has started
+func (t *terminal) forward(w io.Writer) {
+	// only the command holds the terminal open, so reads end when it and its children exit
+	_ = t.slave.Close()
+	t.done = make(chan struct{})
+	go func() {
+		defer close(t.done)
+		_, _ = io.Copy(w, t.master) // ends with EIO when the terminal is closed
+	}()
+}
+
+// close waits for the remaining output and closes the terminal
+func (t *terminal) close() {
+	_ = t.slave.Close()
+	if t.done != nil {
+		select {
+		case <-t.done:
+		case <-time.After(terminalDrainTimeout):
+		}
+	}
+	_ = t.master.Close()
+	if t.done != nil {
+		<-t.done
+	}
+}
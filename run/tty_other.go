@@ -0,0 +1,26 @@
+//go:build !linux
+
+package run
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+)
+
+// terminalSupported is true on platforms where TTY runs commands under a pseudo-terminal
+const terminalSupported = false
+
+// terminal is a pseudo-terminal the command is attached to, not supported on this platform
+type terminal struct{}
+
+func openTerminal() (*terminal, error) {
+	return nil, fmt.Errorf("pseudo-terminals are not supported on %s", runtime.GOOS)
+}
+
+func (t *terminal) attach(_ *exec.Cmd) {}
+
+func (t *terminal) forward(_ io.Writer) {}
+
+func (t *terminal) close() {}
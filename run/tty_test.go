@@ -0,0 +1,69 @@
+package run
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/anchore/go-make/require"
+)
+
+func Test_TTY(t *testing.T) {
+	testapp := buildTestApp(t)
+
+	out, err := Command(testapp, Args("isatty", "1"))
+	require.NoError(t, err)
+	require.Equal(t, "stdout is not a terminal", out)
+
+	if !terminalSupported {
+		// runs without a terminal, rather than failing
+		out, err = Command(testapp, Args("isatty", "1"), TTY())
+		require.NoError(t, err)
+		require.Equal(t, "stdout is not a terminal", out)
+		return
+	}
+
+	display := bytes.Buffer{}
+	out, err = Command(testapp, Args("isatty", "1", "stdout", "\x1b[31mred\x1b[0m\n", "stderr", "\x1b[1mbold\x1b[0m\n"), TTY(), Stderr(&display))
+	require.NoError(t, err)
+	// stdout and stderr are combined, and captured without colors
+	require.Equal(t, "stdout is a terminal\nred\nbold", out)
+	// the test's stderr is not a terminal, so colors are removed from the displayed output too
+	require.Equal(t, "stdout is a terminal\nred\nbold\n", display.String())
+
+	// output is included in errors
+	_, err = Command(testapp, Args("stderr", "\x1b[31mfailure details\x1b[0m", "exit-code", "2"), TTY(), Quiet())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failure details")
+
+	// a piped command has no terminal
+	out, err = Command(testapp, Args("isatty", "1"), TTY(), Pipe(testapp, Args("cat", "-")))
+	require.NoError(t, err)
+	require.Equal(t, "stdout is not a terminal", out)
+}
+
+func Test_ansiStripper(t *testing.T) {
+	buf := bytes.Buffer{}
+	s := &ansiStripper{w: &buf}
+
+	writes := []string{
+		"plain \x1b[1;31mred\x1b[0m ",
+		"split \x1b[3", "2mgreen\x1b", "[0m ",
+		"title \x1b]0;window title\x07done ",
+		"link \x1b]8;;https://example.com\x1b\\text\x1b]8;;\x1b\\ ",
+		"cursor \x1b[2K\r\x1b[1Aup",
+	}
+	for _, w := range writes {
+		n, err := s.Write([]byte(w))
+		require.NoError(t, err)
+		require.Equal(t, len(w), n)
+	}
+	s.flush()
+	require.Equal(t, "plain red split green title done link text cursor \rup", buf.String())
+
+	// an escape that is not a complete sequence is written on flush
+	buf.Reset()
+	_, _ = s.Write([]byte("trailing \x1b["))
+	require.Equal(t, "trailing ", buf.String())
+	s.flush()
+	require.Equal(t, "trailing \x1b[", buf.String())
+}
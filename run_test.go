@@ -0,0 +1,141 @@
+package gomake
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/anchore/go-make/lang"
+	"github.com/anchore/go-make/require"
+	"github.com/anchore/go-make/run"
+	"github.com/anchore/go-make/shell"
+	"github.com/anchore/go-make/template"
+)
+
+func Test_parseCmd(t *testing.T) {
+	require.SetAndRestore(t, &template.Globals, map[string]any{"Name": "a | b", "Dir": "out"})
+
+	script := parseCmd(`go list './my dir/...' | grep -v "{{Name}}" > {{Dir}}/list.txt && GOOS={{Dir}} xargs go vet`)
+	require.Equal(t, shell.Script{
+		{Pipeline: []shell.Command{
+			{Args: []shell.Word{{Value: "go"}, {Value: "list"}, {Value: "./my dir/..."}}},
+			{
+				Args:      []shell.Word{{Value: "grep"}, {Value: "-v"}, {Value: "a | b"}},
+				Redirects: []shell.Redirect{{FD: 1, Op: ">", Target: "out/list.txt"}},
+			},
+		}},
+		{Op: "&&", Pipeline: []shell.Command{
+			{Env: []string{"GOOS=out"}, Args: []shell.Word{{Value: "xargs"}, {Value: "go"}, {Value: "vet"}}},
+		}},
+	}, script)
+
+	err := lang.Catch(func() {
+		parseCmd("go list ./... | ")
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "missing command")
+
+	err = lang.Catch(func() {
+		parseCmd("  ")
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "empty command")
+}
+
+func Test_RunPipeline(t *testing.T) {
+	// the second command ignores its input, the output of the last command is returned
+	require.Equal(t, runtime.GOARCH, Run(`go env GOOS | go env GOARCH`))
+}
+
+func Test_RunEnvPrefix(t *testing.T) {
+	require.Equal(t, "plan9", Run(`GOOS=plan9 go env GOOS`))
+	require.Equal(t, runtime.GOOS, Run(`go env GOOS`))
+}
+
+func Test_RunRedirects(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out dir", "out.txt")
+	require.NoError(t, os.MkdirAll(filepath.Dir(out), 0o755))
+
+	require.Equal(t, "", Run(`go env GOOS > "`+out+`"`))
+	require.Equal(t, "", Run(`go env GOARCH | go env GOARCH >> "`+out+`"`))
+	contents, err := os.ReadFile(out)
+	require.NoError(t, err)
+	require.Equal(t, runtime.GOOS+"\n"+runtime.GOARCH+"\n", string(contents))
+
+	// stderr is returned along with stdout
+	output := Run(`go env -unknown-flag 2>&1`, run.NoFail())
+	require.Contains(t, output, "-unknown-flag")
+
+	// both stdout and stderr written to the file
+	Run(`go env -unknown-flag &> "`+out+`"`, run.NoFail())
+	contents, err = os.ReadFile(out)
+	require.NoError(t, err)
+	require.Contains(t, string(contents), "-unknown-flag")
+
+	err = lang.Catch(func() {
+		Run(`go env GOOS < "` + filepath.Join(t.TempDir(), "missing") + `"`)
+	})
+	require.Error(t, err)
+
+	err = lang.Catch(func() {
+		Run(`go env GOOS > "` + out + `" | go env GOARCH`)
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "last command of a pipeline")
+}
+
+func Test_RunSequences(t *testing.T) {
+	require.Equal(t, runtime.GOOS+"\n"+runtime.GOARCH, Run(`go env GOOS ; go env GOARCH`))
+	require.Equal(t, runtime.GOOS+"\n"+runtime.GOARCH, Run(`go env GOOS && go env GOARCH`))
+	require.Equal(t, runtime.GOOS, Run(`go env GOOS || go env GOARCH`))
+	require.Equal(t, runtime.GOARCH, Run(`go env -unknown-flag || go env GOARCH`, run.Quiet()))
+	require.Equal(t, runtime.GOARCH, Run(`go env -unknown-flag && go env GOOS || go env GOARCH`, run.Quiet()))
+
+	err := lang.Catch(func() {
+		Run(`go env -unknown-flag && go env GOOS`, run.Quiet())
+	})
+	require.Error(t, err)
+
+	// only the result of the last command run fails
+	err = lang.Catch(func() {
+		Run(`go env GOOS ; go env -unknown-flag`, run.Quiet())
+	})
+	require.Error(t, err)
+}
+
+func Test_expandArgs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.txt", "a.txt", "sub/c.txt", "d.go"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, nil, 0o600))
+	}
+	glob := filepath.ToSlash(dir)
+
+	require.Equal(t, []string{
+		"-v",
+		filepath.Join(dir, "a.txt"),
+		filepath.Join(dir, "b.txt"),
+		filepath.Join(dir, "sub", "c.txt"),
+		glob + "/*.md",
+		glob + "/*.go",
+	}, expandArgs([]shell.Word{
+		{Value: "-v"},
+		{Value: glob + "/**/*.txt", Glob: true},
+		{Value: glob + "/*.md", Glob: true},
+		{Value: glob + "/*.go"},
+	}))
+}
+
+func Test_RunVariables(t *testing.T) {
+	t.Setenv("RUN_TEST_VAR", "GOARCH")
+	t.Setenv("RUN_TEST_GOOS", "plan9")
+
+	require.Equal(t, runtime.GOARCH, Run(`go env $RUN_TEST_VAR`))
+	require.Equal(t, runtime.GOOS, Run(`go env ${RUN_TEST_UNSET:-GOOS}`))
+	require.Equal(t, "plan9", Run(`GOOS=$RUN_TEST_GOOS go env GOOS`))
+
+	// single quoted values are not expanded, go env prints an empty line for unknown names
+	require.Equal(t, "", Run(`go env '$RUN_TEST_VAR'`))
+}
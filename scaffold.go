@@ -0,0 +1,168 @@
+package gomake
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/anchore/go-make/color"
+	"github.com/anchore/go-make/lang"
+	"github.com/anchore/go-make/log"
+	"github.com/anchore/go-make/run"
+	"github.com/anchore/go-make/script"
+	"github.com/anchore/go-make/template"
+)
+
+// Scaffold creates files from templates, such as a new command or package, as the task
+// new:<Name>. Run it with `make new <Name>`, providing the Values as KEY=VALUE arguments,
+// e.g. `make new cmd Name=server`, or GOMAKE_<KEY> environment variables, e.g. GOMAKE_NAME;
+// values not provided are prompted for. Existing files are kept, unless run with --overwrite, and --diff shows the changes
+// without writing anything.
+//
+// Example:
+//
+//	//go:embed all:scaffolds/service
+//	var service embed.FS
+//
+//	Makefile(
+//	    Scaffold{
+//	        Name:        "service",
+//	        Description: "create a new service",
+//	        Files:       lang.Return(fs.Sub(service, "scaffolds/service")),
+//	        Dir:         "services/{{.Name}}",
+//	        Values:      List("Name"),
+//	    }.Task(),
+//	)
+type Scaffold struct {
+	// Name is the scaffold name, run with `make new <Name>`
+	Name string
+
+	// Description is shown in help output
+	Description string
+
+	// Files are the template files, rendered with template.RenderDir: file and directory
+	// names are templates, and files ending in .tmpl are rendered and written without the
+	// suffix, while others are copied as-is
+	Files fs.FS
+
+	// Dir is the directory the files are written to relative to the project root, a template,
+	// the project root by default
+	Dir string
+
+	// Values are the names of the values the templates use, such as Name, available as
+	// {{.Name}}; these are required
+	Values []string
+}
+
+// Task returns the new:<Name> task that creates the scaffold's files
+func (s Scaffold) Task() Task {
+	return Task{
+		Name:        "new:" + s.Name,
+		Description: s.Description,
+		Flags:       scaffoldFlags,
+		Run: func() {
+			switch {
+			case hasFlag("--overwrite"):
+				scaffoldMode = template.Overwrite
+			case hasFlag("--diff"):
+				scaffoldMode = template.Diff
+			}
+			s.create(os.Stdout)
+		},
+	}
+}
+
+// scaffoldFlags are the flags of the new tasks: --overwrite replaces existing files and --diff
+// shows the changes to them without writing anything
+var scaffoldFlags = lang.List("--overwrite", "--diff")
+
+// scaffoldMode is how existing files are handled when creating scaffolds, set with the
+// --overwrite and --diff flags
+var scaffoldMode = template.SkipExisting
+
+// create writes the scaffold's files, writing the differences to existing files to w with --diff
+func (s Scaffold) create(w io.Writer) {
+	data := map[string]any{}
+	for _, name := range s.Values {
+		value, _ := template.Globals[name].(string)
+		if value == "" {
+			// not the plain name, which collides with unrelated variables, such as Path on Windows
+			value, _ = run.LookupEnv(scaffoldEnv(name))
+		}
+		if value == "" {
+			value = script.Input(fmt.Sprintf("%s (or run with %s=...):", name, name))
+		}
+		data[name] = value
+	}
+
+	skipped := false
+	for _, f := range template.RenderDir(".", s.Dir, data, template.FS(s.Files), template.Existing(scaffoldMode)) {
+		status := string(f.Status)
+		if scaffoldMode == template.Diff && f.Status != template.Unchanged {
+			status = "would be " + status
+		}
+		switch f.Status {
+		case template.Created, template.Updated:
+			status = color.Green(status)
+		case template.Skipped:
+			skipped = true
+			status = color.Yellow(status)
+		default:
+			status = color.Grey(status)
+		}
+		log.Info("%s %s", status, f.Path)
+		if f.Diff != "" {
+			_, _ = io.WriteString(w, f.Diff)
+		}
+	}
+	if skipped {
+		log.Info(color.Grey("existing files were kept, run with --diff to see the changes or --overwrite to replace them"))
+	}
+}
+
+// scaffoldEnv returns the environment variable providing the scaffold value name, e.g. GOMAKE_NAME
+func scaffoldEnv(name string) string {
+	return "GOMAKE_" + strings.ToUpper(name)
+}
+
+// newTask is the `new` task, listing the scaffolds when run without a scaffold name
+func (t *taskRunner) newTask() *Task {
+	return &Task{
+		Name:        "new",
+		Description: "create files from a scaffold: make new <scaffold> [KEY=VALUE...] [--diff|--overwrite]",
+		Flags:       scaffoldFlags,
+		Run: func() {
+			var scaffolds []string
+			for _, task := range t.tasks {
+				if name, ok := strings.CutPrefix(task.Name, "new:"); ok {
+					scaffolds = append(scaffolds, fmt.Sprintf("  %s  %s", color.Bold(name), task.Description))
+				}
+			}
+			if len(scaffolds) == 0 {
+				log.Info("no scaffolds are configured")
+				return
+			}
+			slices.Sort(scaffolds)
+			fmt.Printf("Scaffolds, run with make new <scaffold>:\n%s\n", strings.Join(scaffolds, "\n"))
+		},
+	}
+}
+
+// scaffoldArgs rewrites `new <scaffold>` to the task new:<scaffold>
+func (t *taskRunner) scaffoldArgs(args []string) []string {
+	if len(args) > 1 && args[0] == "new" && len(t.findByName("new:"+args[1])) > 0 {
+		return append([]string{"new:" + args[1]}, args[2:]...)
+	}
+	return args
+}
+
+// setArgValues sets KEY=VALUE command line arguments as template variables, overriding
+// variables set by the build script
+func setArgValues(values map[string]string) {
+	for key, value := range values {
+		template.Globals[key] = value
+	}
+}
@@ -0,0 +1,87 @@
+package gomake
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/anchore/go-make/file"
+	"github.com/anchore/go-make/lang"
+	"github.com/anchore/go-make/require"
+	"github.com/anchore/go-make/template"
+)
+
+func Test_Scaffold(t *testing.T) {
+	s := Scaffold{
+		Name: "service",
+		Files: fstest.MapFS{
+			"{{.Name}}/main.go.tmpl": {Data: []byte("package main // {{.Name}} {{.Owner}}\n")},
+			"README.md":              {Data: []byte("# {{.Name}}\n")},
+		},
+		Dir:    "services",
+		Values: lang.List("Name", "Owner"),
+	}
+	require.Equal(t, "new:service", s.Task().Name)
+
+	require.SetAndRestore(t, &template.Globals, map[string]any{"Name": "api"})
+	t.Setenv("GOMAKE_OWNER", "team")
+	// plain environment variables may be unrelated, such as Path on Windows
+	t.Setenv("Owner", "unrelated")
+	dir := t.TempDir()
+	file.InDir(dir, func() {
+		out := bytes.Buffer{}
+		s.create(&out)
+		require.Equal(t, "", out.String())
+		requireContents(t, filepath.Join("services", "api", "main.go"), "package main // api team\n")
+		requireContents(t, filepath.Join("services", "README.md"), "# {{.Name}}\n")
+
+		// existing files are kept
+		require.NoError(t, os.WriteFile(filepath.Join("services", "api", "main.go"), []byte("changed\n"), 0o644))
+		s.create(&out)
+		requireContents(t, filepath.Join("services", "api", "main.go"), "changed\n")
+
+		require.SetAndRestore(t, &scaffoldMode, template.Diff)
+		s.create(&out)
+		require.Contains(t, out.String(), "-changed\n+package main // api team\n")
+		requireContents(t, filepath.Join("services", "api", "main.go"), "changed\n")
+
+		scaffoldMode = template.Overwrite
+		s.create(&out)
+		requireContents(t, filepath.Join("services", "api", "main.go"), "package main // api team\n")
+	})
+}
+
+func Test_scaffoldArgs(t *testing.T) {
+	r := &taskRunner{}
+	r.addTasks(Scaffold{Name: "cmd"}.Task(), Task{Name: "build"})
+	r.tasks = append(r.tasks, r.newTask())
+
+	require.Equal(t, []string{"new:cmd", "build"}, r.scaffoldArgs([]string{"new", "cmd", "build"}))
+	require.Equal(t, []string{"new"}, r.scaffoldArgs([]string{"new"}))
+	require.Equal(t, []string{"new", "build"}, r.scaffoldArgs([]string{"new", "build"}))
+	require.Equal(t, []string{"build", "cmd"}, r.scaffoldArgs([]string{"build", "cmd"}))
+}
+
+func Test_makeVariables(t *testing.T) {
+	require.Equal(t, map[string]string{}, makeVariables(""))
+	require.Equal(t, map[string]string{}, makeVariables("s -j4 --jobserver-auth=3,4"))
+	require.Equal(t, map[string]string{"Name": "billing", "Owner": "a team", "Path": `c:\x`, "A": "1", "EMPTY": ""},
+		makeVariables(`s -- Name=billing Owner=a\ team Path=c:\x A:=1 EMPTY=`))
+	require.Equal(t, map[string]string{"Name": "billing"}, makeVariables(" -- Name=billing"))
+}
+
+func Test_setArgValues(t *testing.T) {
+	require.SetAndRestore(t, &template.Globals, map[string]any{"Version": func() string { return "v1" }})
+	setArgValues(map[string]string{"Version": "v2", "Name": "app"})
+	require.Equal(t, "v2 app", template.Render("{{Version}} {{.Name}}"))
+}
+
+func requireContents(t *testing.T, path, expected string) {
+	t.Helper()
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, expected, strings.ReplaceAll(string(contents), "\r\n", "\n"))
+}
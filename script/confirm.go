@@ -5,23 +5,44 @@ import (
 	"strings"
 
 	"github.com/anchore/go-make/color"
-	"github.com/anchore/go-make/lang"
+	"github.com/anchore/go-make/config"
 	"github.com/anchore/go-make/log"
 )
 
-// Confirm prompts the user for a single keypress of y for yes or cancels
+// Confirm prompts the user to answer y for yes or cancels. Confirmation is assumed with
+// --yes / GOMAKE_ASSUME_YES, and fails when stdin is not a terminal.
 //
 //nolint:goprintffuncname
 func Confirm(format string, args ...any) {
+	if !YesNo(fmt.Sprintf(format, args...)) {
+		panic(fmt.Errorf("CANCELLED: "+format, args...))
+	}
+}
+
+// YesNo prompts the user to answer y or n, repeating until one is given. Without a Default,
+// an answer is required. Yes is assumed with --yes / GOMAKE_ASSUME_YES, unless RequireAnswer
+// is given.
+func YesNo(question string, opts ...PromptOption) bool {
+	cfg := newPromptConfig(opts)
+	if config.AssumeYes && !cfg.requireAnswer {
+		log.Info("%s %s", question, color.Grey("(assuming yes)"))
+		return true
+	}
+	hint := " [y/n]"
+	if cfg.defaultValue != nil {
+		switch strings.ToLower(*cfg.defaultValue) {
+		case "y", "yes":
+			hint = " [Y/n]"
+		case "n", "no":
+			hint = " [y/N]"
+		}
+	}
 	for {
-		log.Info(format+" [y/n]", args...)
-		var response string
-		lang.Return(fmt.Scan(&response))
-		switch strings.ToLower(response) {
-		case "y":
-			return // continue
-		case "n":
-			panic(fmt.Errorf("CANCELLED: "+format, args...))
+		switch strings.ToLower(cfg.ask(question+hint, readLine)) {
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
 		default:
 			log.Info(color.Red("Please answer 'y' or 'n'"))
 		}
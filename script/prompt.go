@@ -0,0 +1,244 @@
+package script
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/anchore/go-make/color"
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/log"
+)
+
+// PromptOption configures a prompt
+type PromptOption func(*promptConfig)
+
+type promptConfig struct {
+	defaultValue  *string
+	timeout       time.Duration
+	requireAnswer bool
+}
+
+// Default sets the answer used when the user enters nothing, the prompt times out, or prompts
+// are answered automatically with --yes / GOMAKE_ASSUME_YES. For YesNo, use "y" or "n".
+func Default(value string) PromptOption {
+	return func(c *promptConfig) {
+		c.defaultValue = &value
+	}
+}
+
+// Timeout limits how long a prompt waits for an answer. When it expires, the default is used
+// if one is set, otherwise the prompt fails.
+func Timeout(timeout time.Duration) PromptOption {
+	return func(c *promptConfig) {
+		c.timeout = timeout
+	}
+}
+
+// RequireAnswer asks the prompt even with --yes / GOMAKE_ASSUME_YES, for confirmations that must
+// never be given automatically, such as triggering a production release. The prompt fails when
+// stdin is not a terminal.
+func RequireAnswer() PromptOption {
+	return func(c *promptConfig) {
+		c.requireAnswer = true
+	}
+}
+
+var (
+	// stdin is where answers are read from, overridable in tests
+	stdin io.Reader = os.Stdin
+
+	// isTerminal reports whether stdin is an interactive terminal, overridable in tests
+	isTerminal = func() bool {
+		return term.IsTerminal(int(os.Stdin.Fd())) //nolint:gosec // G115: file descriptors fit in an int
+	}
+
+	// readSecret reads a line from the terminal without echoing it, overridable in tests
+	readSecret = func() (string, error) {
+		b, err := term.ReadPassword(int(os.Stdin.Fd())) //nolint:gosec // G115: file descriptors fit in an int
+		return string(b), err
+	}
+
+	// pendingAnswer holds a read abandoned by a prompt that timed out, so its answer goes to
+	// the next prompt rather than being lost
+	pendingAnswer <-chan answer
+)
+
+type answer struct {
+	value string
+	err   error
+}
+
+// Input prompts for a line of text, returning the default when nothing is entered. Without a
+// default, the prompt repeats until a value is entered.
+func Input(question string, opts ...PromptOption) string {
+	cfg := newPromptConfig(opts)
+	for {
+		value := cfg.ask(question+defaultHint(cfg.defaultValue), readLine)
+		if value != "" || cfg.defaultValue != nil {
+			return value
+		}
+		log.Info(color.Red("Please enter a value"))
+	}
+}
+
+// Password prompts for a secret without echoing it. Assumed answers (--yes) and timeouts only
+// succeed with a Default.
+func Password(question string, opts ...PromptOption) string {
+	cfg := newPromptConfig(opts)
+	for {
+		value := cfg.ask(question, func() (string, error) {
+			value, err := readSecret()
+			// the newline entered was not echoed
+			_, _ = fmt.Fprintln(log.CurrentOutput())
+			return value, err
+		})
+		if value != "" || cfg.defaultValue != nil {
+			return value
+		}
+		log.Info(color.Red("Please enter a value"))
+	}
+}
+
+// Select prompts to choose one of the choices, by number or value, returning the choice. When
+// no Default is given, the first choice is the default.
+func Select(question string, choices []string, opts ...PromptOption) string {
+	if len(choices) == 0 {
+		panic(fmt.Errorf("no choices to select from: %s", question))
+	}
+	cfg := newPromptConfig(append([]PromptOption{Default(choices[0])}, opts...))
+
+	lines := []string{question}
+	for i, choice := range choices {
+		suffix := ""
+		if choice == *cfg.defaultValue {
+			suffix = color.Grey(" (default)")
+		}
+		lines = append(lines, fmt.Sprintf("  %d) %s%s", i+1, choice, suffix))
+	}
+	lines = append(lines, "Enter a number"+defaultHint(cfg.defaultValue)+":")
+
+	for {
+		value := cfg.ask(strings.Join(lines, "\n"), readLine)
+		if n, err := strconv.Atoi(value); err == nil && n > 0 && n <= len(choices) {
+			return choices[n-1]
+		}
+		if slices.Contains(choices, value) {
+			return value
+		}
+		log.Info(color.Red("Please enter a number from 1 to %d"), len(choices))
+	}
+}
+
+func newPromptConfig(opts []PromptOption) *promptConfig {
+	cfg := &promptConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// ask shows the question and returns the trimmed answer, or the default when nothing is entered.
+// Fails when the question can't be answered: stdin is not a terminal, stdin is closed, or the
+// timeout expires without a default.
+func (c *promptConfig) ask(question string, read func() (string, error)) string {
+	if config.AssumeYes && !c.requireAnswer {
+		if c.defaultValue == nil {
+			panic(fmt.Errorf("unable to answer %q automatically: no default answer", firstLine(question)))
+		}
+		log.Info("%s %s", question, color.Grey("(assuming %q)", *c.defaultValue))
+		return *c.defaultValue
+	}
+	if !isTerminal() {
+		if c.requireAnswer {
+			panic(fmt.Errorf("unable to prompt %q: stdin is not a terminal, and it must be answered interactively", firstLine(question)))
+		}
+		panic(fmt.Errorf("unable to prompt %q: stdin is not a terminal; run with --yes or set GOMAKE_ASSUME_YES=true to accept defaults", firstLine(question)))
+	}
+
+	log.Info(question)
+	value, err := c.await(read)
+	if errors.Is(err, errTimeout) && c.defaultValue != nil {
+		log.Info(color.Grey("no answer after %v, using %q", c.timeout, *c.defaultValue))
+		return *c.defaultValue
+	}
+	if err != nil {
+		panic(fmt.Errorf("unable to read answer to %q: %w", firstLine(question), err))
+	}
+	value = strings.TrimSpace(value)
+	if value == "" && c.defaultValue != nil {
+		return *c.defaultValue
+	}
+	return value
+}
+
+var errTimeout = errors.New("timed out")
+
+// await reads an answer, waiting at most the configured timeout
+func (c *promptConfig) await(read func() (string, error)) (string, error) {
+	results := pendingAnswer
+	pendingAnswer = nil
+	if results == nil {
+		ch := make(chan answer, 1)
+		go func() {
+			value, err := read()
+			ch <- answer{value, err}
+		}()
+		results = ch
+	}
+
+	var timeout <-chan time.Time
+	if c.timeout > 0 {
+		timer := time.NewTimer(c.timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case a := <-results:
+		return a.value, a.err
+	case <-timeout:
+		pendingAnswer = results
+		return "", fmt.Errorf("%w after %v", errTimeout, c.timeout)
+	}
+}
+
+// readLine reads a single line from stdin, one byte at a time so nothing past the line is
+// consumed
+func readLine() (string, error) {
+	var line []byte
+	buf := []byte{0}
+	for {
+		n, err := stdin.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				return strings.TrimSuffix(string(line), "\r"), nil
+			}
+			line = append(line, buf[0])
+		}
+		if errors.Is(err, io.EOF) && len(line) > 0 {
+			return string(line), nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+func defaultHint(defaultValue *string) string {
+	if defaultValue == nil || *defaultValue == "" {
+		return ""
+	}
+	return fmt.Sprintf(" [%s]", *defaultValue)
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,231 @@
+package script
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/lang"
+	"github.com/anchore/go-make/log"
+	"github.com/anchore/go-make/require"
+)
+
+// setupPrompt answers prompts with the given input as if typed at a terminal
+func setupPrompt(t *testing.T, input string) *strings.Builder {
+	out := &strings.Builder{}
+	require.SetAndRestore(t, &stdin, io.Reader(strings.NewReader(input)))
+	require.SetAndRestore(t, &isTerminal, func() bool { return true })
+	require.SetAndRestore(t, &config.AssumeYes, false)
+	require.SetAndRestore(t, &log.Output, io.Writer(out))
+	require.SetAndRestore(t, &pendingAnswer, nil)
+	return out
+}
+
+func Test_Input(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     []PromptOption
+		expected string
+	}{
+		{
+			name:     "answered",
+			input:    "  some value \n",
+			expected: "some value",
+		},
+		{
+			name:     "default",
+			input:    "\n",
+			opts:     []PromptOption{Default("the default")},
+			expected: "the default",
+		},
+		{
+			name:     "repeats until answered",
+			input:    "\n\r\nfinally\n",
+			expected: "finally",
+		},
+		{
+			name:     "last line without newline",
+			input:    "value",
+			expected: "value",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupPrompt(t, tt.input)
+			require.Equal(t, tt.expected, Input("question?", tt.opts...))
+		})
+	}
+}
+
+func Test_Select(t *testing.T) {
+	choices := []string{"v1.0.0", "v1.0.1", "v2.0.0"}
+	tests := []struct {
+		name     string
+		input    string
+		opts     []PromptOption
+		expected string
+	}{
+		{
+			name:     "by number",
+			input:    "2\n",
+			expected: "v1.0.1",
+		},
+		{
+			name:     "by value",
+			input:    "v2.0.0\n",
+			expected: "v2.0.0",
+		},
+		{
+			name:     "first choice is the default",
+			input:    "\n",
+			expected: "v1.0.0",
+		},
+		{
+			name:     "explicit default",
+			input:    "\n",
+			opts:     []PromptOption{Default("v2.0.0")},
+			expected: "v2.0.0",
+		},
+		{
+			name:     "repeats until valid",
+			input:    "4\nv3\n3\n",
+			expected: "v2.0.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := setupPrompt(t, tt.input)
+			require.Equal(t, tt.expected, Select("which version?", choices, tt.opts...))
+			require.Contains(t, out.String(), "3) v2.0.0")
+		})
+	}
+}
+
+func Test_YesNo(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     []PromptOption
+		expected bool
+	}{
+		{
+			name:     "yes",
+			input:    "y\n",
+			expected: true,
+		},
+		{
+			name:     "no",
+			input:    "No\n",
+			expected: false,
+		},
+		{
+			name:     "repeats until answered",
+			input:    "\nmaybe\nyes\n",
+			expected: true,
+		},
+		{
+			name:     "default",
+			input:    "\n",
+			opts:     []PromptOption{Default("n")},
+			expected: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupPrompt(t, tt.input)
+			require.Equal(t, tt.expected, YesNo("continue?", tt.opts...))
+		})
+	}
+}
+
+func Test_Confirm(t *testing.T) {
+	setupPrompt(t, "y\nn\n")
+	require.NoError(t, lang.Catch(func() {
+		Confirm("release %s?", "v1.0.0")
+	}))
+	err := lang.Catch(func() {
+		Confirm("release %s?", "v1.0.0")
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "CANCELLED: release v1.0.0?")
+}
+
+func Test_Password(t *testing.T) {
+	setupPrompt(t, "")
+	require.SetAndRestore(t, &readSecret, func() (string, error) { return "s3cr3t", nil })
+	require.Equal(t, "s3cr3t", Password("token:"))
+}
+
+func Test_Timeout(t *testing.T) {
+	setupPrompt(t, "")
+	blocked := make(chan struct{})
+	t.Cleanup(func() { close(blocked) })
+	require.SetAndRestore(t, &stdin, io.Reader(blockingReader(blocked)))
+
+	// the default is used when no answer arrives in time
+	require.Equal(t, "fallback", Input("question?", Default("fallback"), Timeout(10*time.Millisecond)))
+
+	// without a default, the prompt fails
+	err := lang.Catch(func() {
+		Input("question?", Timeout(10*time.Millisecond))
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "timed out")
+}
+
+func Test_notTerminal(t *testing.T) {
+	setupPrompt(t, "y\n")
+	require.SetAndRestore(t, &isTerminal, func() bool { return false })
+
+	err := lang.Catch(func() {
+		Confirm("continue?")
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "stdin is not a terminal")
+	require.Contains(t, err.Error(), "--yes")
+}
+
+func Test_assumeYes(t *testing.T) {
+	setupPrompt(t, "")
+	require.SetAndRestore(t, &isTerminal, func() bool { return false })
+	require.SetAndRestore(t, &config.AssumeYes, true)
+
+	require.NoError(t, lang.Catch(func() {
+		Confirm("continue?")
+	}))
+	require.True(t, YesNo("continue?", Default("n")))
+	require.Equal(t, "v1.0.0", Select("which version?", []string{"v1.0.0", "v2.0.0"}))
+	require.Equal(t, "default", Input("question?", Default("default")))
+
+	// without a default there is nothing to assume
+	err := lang.Catch(func() {
+		Input("question?")
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no default answer")
+}
+
+func Test_RequireAnswer(t *testing.T) {
+	setupPrompt(t, "n\n")
+	require.SetAndRestore(t, &config.AssumeYes, true)
+
+	// answered at the terminal despite --yes
+	require.False(t, YesNo("release v1.0.0?", RequireAnswer()))
+
+	require.SetAndRestore(t, &isTerminal, func() bool { return false })
+	err := lang.Catch(func() {
+		YesNo("release v1.0.0?", RequireAnswer())
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "must be answered interactively")
+}
+
+type blockingReader chan struct{}
+
+func (b blockingReader) Read([]byte) (int, error) {
+	<-b
+	return 0, io.EOF
+}
@@ -0,0 +1,399 @@
+package shell
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Script is a parsed command line: a sequence of pipelines, see Parse
+type Script []Step
+
+// Step is a pipeline run depending on the result of the previous step: always for the first
+// step and after ";", when the previous step succeeded after "&&", and when it failed after "||"
+type Step struct {
+	// Op is the operator preceding the pipeline: "" for the first step, ";", "&&" or "||"
+	Op string
+
+	// Pipeline is the commands of the step, each piping its stdout to the next
+	Pipeline []Command
+}
+
+// Command is a single command of a pipeline
+type Command struct {
+	// Env are NAME=value assignments preceding the command, set in its environment
+	Env []string
+
+	// Args are the command and its arguments
+	Args []Word
+
+	// Redirects are the command's input and output redirections, in order
+	Redirects []Redirect
+}
+
+// Word is a command argument with quotes removed
+type Word struct {
+	// Value is the argument
+	Value string
+
+	// Glob is true when the argument is unquoted and contains the glob characters *, ? or [
+	Glob bool
+}
+
+// Redirect is an input or output redirection of a command
+type Redirect struct {
+	// FD is the redirected file descriptor: 0 for stdin, 1 for stdout and 2 for stderr
+	FD int
+
+	// Op is "<" to read from a file, ">" to write to a file, ">>" to append to a file, or ">&"
+	// to write to another file descriptor
+	Op string
+
+	// Target is the file name, or the file descriptor for ">&"
+	Target string
+}
+
+// Parse parses a command line using a subset of POSIX shell syntax, without running a shell:
+//   - quoting with '...', "..." and `...`, and \ escaping special characters outside of quotes
+//     and ", \, $ and ` within double quotes; any other \ is kept, so Windows paths work as-is
+//   - {{template directives}} are kept as part of the word they appear in
+//   - NAME=value assignments preceding a command
+//   - redirections: < file, > file, >> file, 2> file, 2>> file, 2>&1, >&2 and &> file
+//   - pipelines with |, and sequences with &&, || and ;
+//
+// $VAR is kept as-is, see ParseEnv to expand variables. Command substitution, subshells and
+// background jobs are not supported.
+func Parse(s string) (Script, error) {
+	return ParseEnv(s, nil)
+}
+
+// ParseEnv parses a command line the same as Parse, also expanding $NAME, ${NAME},
+// ${NAME:-default} (the default when unset or empty) and ${NAME-default} (the default when
+// unset) with values from lookup, outside of single quotes. As in a shell, unquoted values are
+// split into separate arguments at whitespace, and an unquoted empty value is no argument at
+// all, but values are not expanded as globs. A $ not followed by a name is kept as-is.
+func ParseEnv(s string, lookup func(name string) (string, bool)) (Script, error) {
+	tokens, err := tokenize(s, lookup)
+	if err != nil {
+		return nil, err
+	}
+
+	var script Script
+	step := Step{}
+	cmd := Command{}
+	afterPipe := false
+	endCommand := func(next string) error {
+		if len(cmd.Args) == 0 {
+			if len(cmd.Env) > 0 || len(cmd.Redirects) > 0 || next != "" || afterPipe {
+				return fmt.Errorf("missing command before %s in: %s", describeOp(next), s)
+			}
+			return nil
+		}
+		step.Pipeline = append(step.Pipeline, cmd)
+		cmd = Command{}
+		afterPipe = next == "|"
+		return nil
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch {
+		case tok.op == "|":
+			if err := endCommand(tok.op); err != nil {
+				return nil, err
+			}
+		case tok.op == "&&" || tok.op == "||" || tok.op == ";":
+			if err := endCommand(tok.op); err != nil {
+				return nil, err
+			}
+			script = append(script, step)
+			step = Step{Op: tok.op}
+		case tok.op != "":
+			redirect, err := parseRedirect(tok.op)
+			if err != nil {
+				return nil, err
+			}
+			if redirect.Op != ">&" {
+				if i+1 >= len(tokens) || tokens[i+1].op != "" {
+					return nil, fmt.Errorf("missing file name after %s in: %s", tok.op, s)
+				}
+				i++
+				redirect.Target = tokens[i].word.Value
+			}
+			if tok.op == "&>" {
+				// both stdout and stderr to the file
+				cmd.Redirects = append(cmd.Redirects, redirect, Redirect{FD: 2, Op: ">&", Target: "1"})
+				continue
+			}
+			cmd.Redirects = append(cmd.Redirects, redirect)
+		case len(cmd.Args) == 0 && tok.assignment:
+			cmd.Env = append(cmd.Env, tok.word.Value)
+		default:
+			cmd.Args = append(cmd.Args, tok.word)
+		}
+	}
+	if err := endCommand(""); err != nil {
+		return nil, err
+	}
+	if len(step.Pipeline) > 0 {
+		script = append(script, step)
+	} else if step.Op != "" && step.Op != ";" {
+		return nil, fmt.Errorf("missing command after %s in: %s", step.Op, s)
+	}
+	return script, nil
+}
+
+func describeOp(op string) string {
+	if op == "" {
+		return "end of line"
+	}
+	return op
+}
+
+// parseRedirect parses a redirection operator such as 2>> or 2>&1
+func parseRedirect(op string) (Redirect, error) {
+	if op == "&>" {
+		return Redirect{FD: 1, Op: ">"}, nil
+	}
+	digits := strings.IndexFunc(op, func(r rune) bool { return r < '0' || r > '9' })
+	fd, operator := op[:digits], op[digits:]
+	r := Redirect{FD: 1, Op: operator}
+	if operator == "<" {
+		r.FD = 0
+	}
+	if fd != "" {
+		r.FD, _ = strconv.Atoi(fd)
+	}
+	if target, ok := strings.CutPrefix(operator, ">&"); ok {
+		r.Op = ">&"
+		r.Target = target
+	}
+	if r.FD > 2 || (r.Op == ">&" && r.Target != "1" && r.Target != "2") {
+		return r, fmt.Errorf("unsupported redirection: %s", op)
+	}
+	return r, nil
+}
+
+type token struct {
+	op         string
+	word       Word
+	assignment bool
+}
+
+// tokenize splits the command line into words and operators, expanding variables when lookup
+// is not nil
+func tokenize(s string, lookup func(string) (string, bool)) ([]token, error) {
+	var out []token
+	word := strings.Builder{}
+	inWord := false
+	quoted := false
+	glob := false
+	// the length of word when the first unquoted = was found, for NAME=value assignments
+	assignAt := -1
+
+	endWord := func() {
+		if !inWord {
+			return
+		}
+		value := word.String()
+		out = append(out, token{
+			word:       Word{Value: value, Glob: glob && !quoted},
+			assignment: assignAt > 0 && isName(value[:assignAt]),
+		})
+		word.Reset()
+		inWord, quoted, glob, assignAt = false, false, false, -1
+	}
+
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case strings.HasPrefix(s[i:], "{{"):
+			// template directives are copied verbatim, they are rendered after parsing
+			end := strings.Index(s[i:], "}}")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated template directive in: %s", s)
+			}
+			word.WriteString(s[i : i+end+2])
+			inWord = true
+			i += end + 1
+		case ch == '\'' || ch == '`':
+			end := strings.IndexByte(s[i+1:], ch)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated %c quote in: %s", ch, s)
+			}
+			word.WriteString(s[i+1 : i+1+end])
+			inWord, quoted = true, true
+			i += end + 1
+		case ch == '"':
+			end, err := readDoubleQuoted(s, i+1, &word, lookup)
+			if err != nil {
+				return nil, err
+			}
+			inWord, quoted = true, true
+			i = end
+		case ch == '\\' && i+1 < len(s) && strings.IndexByte(" \t\n'\"`\\$|&;<>*?[]#(){}=", s[i+1]) >= 0:
+			word.WriteByte(s[i+1])
+			inWord = true
+			i++
+		case ch == '$' && lookup != nil:
+			value, end, err := expandVariable(s, i, lookup)
+			if err != nil {
+				return nil, err
+			}
+			if end == i {
+				word.WriteByte(ch)
+				inWord = true
+				continue
+			}
+			i = end
+			if assignAt > 0 && isName(word.String()[:assignAt]) {
+				// assignment values are not split
+				word.WriteString(value)
+				continue
+			}
+			fields := strings.Fields(value)
+			for j, field := range fields {
+				// whitespace in the value separates arguments
+				if j > 0 || !strings.HasPrefix(value, field) {
+					endWord()
+				}
+				word.WriteString(field)
+				inWord = true
+			}
+			if len(fields) > 0 && !strings.HasSuffix(value, fields[len(fields)-1]) {
+				endWord()
+			}
+		case ch == ' ' || ch == '\t' || ch == '\r' || ch == '\n':
+			endWord()
+		case ch == '|' || ch == '&' || ch == ';' || ch == '<' || ch == '>':
+			literal := readOperator(s[i:])
+			op := literal
+			if op == "&" {
+				return nil, fmt.Errorf("background commands (&) are not supported in: %s", s)
+			}
+			// a file descriptor number directly preceding a redirection is part of it, as in 2>
+			if (ch == '<' || ch == '>') && inWord && !quoted && isDigits(word.String()) {
+				op = word.String() + op
+				word.Reset()
+				inWord = false
+			}
+			endWord()
+			out = append(out, token{op: op})
+			i += len(literal) - 1
+		default:
+			if ch == '=' && assignAt < 0 && !quoted {
+				assignAt = word.Len()
+			}
+			if ch == '*' || ch == '?' || ch == '[' {
+				glob = true
+			}
+			word.WriteByte(ch)
+			inWord = true
+		}
+	}
+	endWord()
+	return out, nil
+}
+
+// readOperator returns the operator at the start of s
+func readOperator(s string) string {
+	for _, op := range []string{"&&", "||", ">>", "&>", ">&1", ">&2", "|", "&", ";", "<", ">"} {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// readDoubleQuoted writes the contents of the double-quoted string starting at s[start] to w,
+// expanding variables when lookup is not nil, returning the index of the closing quote
+func readDoubleQuoted(s string, start int, w *strings.Builder, lookup func(string) (string, bool)) (int, error) {
+	for i := start; i < len(s); i++ {
+		switch {
+		case s[i] == '"':
+			return i, nil
+		case s[i] == '$' && lookup != nil:
+			value, end, err := expandVariable(s, i, lookup)
+			if err != nil {
+				return 0, err
+			}
+			if end == i {
+				w.WriteByte('$')
+				continue
+			}
+			w.WriteString(value)
+			i = end
+		case s[i] == '\\' && i+1 < len(s) && strings.IndexByte("\"\\$`", s[i+1]) >= 0:
+			w.WriteByte(s[i+1])
+			i++
+		case strings.HasPrefix(s[i:], "{{"):
+			end := strings.Index(s[i:], "}}")
+			if end < 0 {
+				return 0, fmt.Errorf("unterminated template directive in: %s", s)
+			}
+			w.WriteString(s[i : i+end+2])
+			i += end + 1
+		default:
+			w.WriteByte(s[i])
+		}
+	}
+	return 0, fmt.Errorf(`unterminated " quote in: %s`, s)
+}
+
+// expandVariable expands the variable reference at s[start], a $, returning its value and the
+// index of its last character, or start when it is not a reference
+func expandVariable(s string, start int, lookup func(string) (string, bool)) (string, int, error) {
+	rest := s[start+1:]
+	if !strings.HasPrefix(rest, "{") {
+		name := readName(rest)
+		if name == "" {
+			return "", start, nil
+		}
+		value, _ := lookup(name)
+		return value, start + len(name), nil
+	}
+
+	end := strings.IndexByte(rest, '}')
+	if end < 0 {
+		return "", 0, fmt.Errorf("unterminated ${ in: %s", s)
+	}
+	ref := rest[1:end]
+	name := readName(ref)
+	value, set := lookup(name)
+	switch op := ref[len(name):]; {
+	case name == "":
+		return "", 0, fmt.Errorf("bad substitution ${%s} in: %s", ref, s)
+	case op == "":
+	case strings.HasPrefix(op, ":-"):
+		if value == "" {
+			value = op[2:]
+		}
+	case strings.HasPrefix(op, "-"):
+		if !set {
+			value = op[1:]
+		}
+	default:
+		return "", 0, fmt.Errorf("unsupported substitution ${%s} in: %s", ref, s)
+	}
+	return value, start + 1 + end, nil
+}
+
+// readName returns the variable name at the start of s
+func readName(s string) string {
+	for i, ch := range s {
+		if ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (i > 0 && ch >= '0' && ch <= '9') {
+			continue
+		}
+		return s[:i]
+	}
+	return s
+}
+
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+// isName returns true for valid environment variable names
+func isName(s string) bool {
+	return s != "" && readName(s) == s
+}
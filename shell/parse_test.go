@@ -0,0 +1,213 @@
+package shell_test
+
+import (
+	"testing"
+
+	"github.com/anchore/go-make/require"
+	"github.com/anchore/go-make/shell"
+)
+
+// cmd is a shorthand for a command of plain words
+func cmd(args ...string) shell.Command {
+	c := shell.Command{}
+	for _, arg := range args {
+		c.Args = append(c.Args, shell.Word{Value: arg})
+	}
+	return c
+}
+
+func Test_Parse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected shell.Script
+	}{
+		{
+			input:    "",
+			expected: nil,
+		},
+		{
+			input:    "go build ./...",
+			expected: shell.Script{{Pipeline: []shell.Command{cmd("go", "build", "./...")}}},
+		},
+		{
+			input:    `echo 'single quoted' "double \"quoted\" \$HOME" --name="joined value"x`,
+			expected: shell.Script{{Pipeline: []shell.Command{cmd("echo", "single quoted", `double "quoted" $HOME`, "--name=joined valuex")}}},
+		},
+		{
+			input:    `echo escaped\ space \| C:\Users\me`,
+			expected: shell.Script{{Pipeline: []shell.Command{cmd("echo", "escaped space", "|", `C:\Users\me`)}}},
+		},
+		{
+			input:    `echo {{ .Value | upper }} "{{ "a b" }}"`,
+			expected: shell.Script{{Pipeline: []shell.Command{cmd("echo", "{{ .Value | upper }}", `{{ "a b" }}`)}}},
+		},
+		{
+			input: `CGO_ENABLED=0 GOOS="linux os" go build -ldflags=-s NOT=ENV`,
+			expected: shell.Script{{Pipeline: []shell.Command{{
+				Env:  []string{"CGO_ENABLED=0", "GOOS=linux os"},
+				Args: cmd("go", "build", "-ldflags=-s", "NOT=ENV").Args,
+			}}}},
+		},
+		{
+			input:    `"QUOTED=name" cmd`,
+			expected: shell.Script{{Pipeline: []shell.Command{cmd("QUOTED=name", "cmd")}}},
+		},
+		{
+			input: `ls *.go 'lit*.go' dir/**/x?[ab].txt`,
+			expected: shell.Script{{Pipeline: []shell.Command{{Args: []shell.Word{
+				{Value: "ls"},
+				{Value: "*.go", Glob: true},
+				{Value: "lit*.go"},
+				{Value: "dir/**/x?[ab].txt", Glob: true},
+			}}}}},
+		},
+		{
+			input: `go test ./... > out.txt 2>&1 < in.txt`,
+			expected: shell.Script{{Pipeline: []shell.Command{{
+				Args: cmd("go", "test", "./...").Args,
+				Redirects: []shell.Redirect{
+					{FD: 1, Op: ">", Target: "out.txt"},
+					{FD: 2, Op: ">&", Target: "1"},
+					{FD: 0, Op: "<", Target: "in.txt"},
+				},
+			}}}},
+		},
+		{
+			input: `cmd >>log.txt 2>> err.txt >&2 1>&2 &> all.txt a2>b`,
+			expected: shell.Script{{Pipeline: []shell.Command{{
+				Args: cmd("cmd", "a2").Args,
+				Redirects: []shell.Redirect{
+					{FD: 1, Op: ">>", Target: "log.txt"},
+					{FD: 2, Op: ">>", Target: "err.txt"},
+					{FD: 1, Op: ">&", Target: "2"},
+					{FD: 1, Op: ">&", Target: "2"},
+					{FD: 1, Op: ">", Target: "all.txt"},
+					{FD: 2, Op: ">&", Target: "1"},
+					{FD: 1, Op: ">", Target: "b"},
+				},
+			}}}},
+		},
+		{
+			input: `go list ./... | grep -v /test/ && echo ok || echo failed; echo done;`,
+			expected: shell.Script{
+				{Pipeline: []shell.Command{cmd("go", "list", "./..."), cmd("grep", "-v", "/test/")}},
+				{Op: "&&", Pipeline: []shell.Command{cmd("echo", "ok")}},
+				{Op: "||", Pipeline: []shell.Command{cmd("echo", "failed")}},
+				{Op: ";", Pipeline: []shell.Command{cmd("echo", "done")}},
+			},
+		},
+		{
+			input: `test -f x||true`,
+			expected: shell.Script{
+				{Pipeline: []shell.Command{cmd("test", "-f", "x")}},
+				{Op: "||", Pipeline: []shell.Command{cmd("true")}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := shell.Parse(tt.input)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func Test_Parse_errors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: `echo "unterminated`, expected: `unterminated " quote`},
+		{input: `echo 'unterminated`, expected: "unterminated ' quote"},
+		{input: `echo {{ .Value`, expected: "unterminated template"},
+		{input: `go list |`, expected: "missing command before end of line"},
+		{input: `| grep x`, expected: "missing command before |"},
+		{input: `a && && b`, expected: "missing command before &&"},
+		{input: `go build &&`, expected: "missing command after &&"},
+		{input: `FOO=bar`, expected: "missing command"},
+		{input: `> out.txt`, expected: "missing command"},
+		{input: `echo >`, expected: "missing file name after >"},
+		{input: `echo > | cat`, expected: "missing file name after >"},
+		{input: `echo 3>x`, expected: "unsupported redirection: 3>"},
+		{input: `sleep 10 &`, expected: "background commands"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := shell.Parse(tt.input)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func Test_ParseEnv(t *testing.T) {
+	env := map[string]string{
+		"HOME":  "/home/me",
+		"FLAGS": " -v  -race ",
+		"EMPTY": "",
+		"SPACE": "a b",
+	}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		input    string
+		expected shell.Script
+	}{
+		{
+			input:    `ls $HOME/bin ${HOME}x '$HOME' "$HOME" \$HOME $ $1 a$`,
+			expected: shell.Script{{Pipeline: []shell.Command{cmd("ls", "/home/me/bin", "/home/mex", "$HOME", "/home/me", "$HOME", "$", "$1", "a$")}}},
+		},
+		{
+			input:    `go test $FLAGS ./... "$FLAGS" $EMPTY "$EMPTY" x$EMPTY $UNSET`,
+			expected: shell.Script{{Pipeline: []shell.Command{cmd("go", "test", "-v", "-race", "./...", " -v  -race ", "", "x")}}},
+		},
+		{
+			input:    `echo pre$FLAGS-post pre${SPACE}post`,
+			expected: shell.Script{{Pipeline: []shell.Command{cmd("echo", "pre", "-v", "-race", "-post", "prea", "bpost")}}},
+		},
+		{
+			input:    `echo ${VERSION:-dev} ${EMPTY:-default} ${EMPTY-default} ${UNSET-default} "${HOME:-unused}"`,
+			expected: shell.Script{{Pipeline: []shell.Command{cmd("echo", "dev", "default", "default", "/home/me")}}},
+		},
+		{
+			input: `OUT=$SPACE go build > $HOME/out.txt`,
+			expected: shell.Script{{Pipeline: []shell.Command{{
+				Env:       []string{"OUT=a b"},
+				Args:      cmd("go", "build").Args,
+				Redirects: []shell.Redirect{{FD: 1, Op: ">", Target: "/home/me/out.txt"}},
+			}}}},
+		},
+		{
+			// expanded values are not globs
+			input:    `ls ${PATTERN:-*.go}`,
+			expected: shell.Script{{Pipeline: []shell.Command{cmd("ls", "*.go")}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			script, err := shell.ParseEnv(tt.input, lookup)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, script)
+		})
+	}
+
+	// not expanded without a lookup
+	script, err := shell.Parse(`echo $HOME`)
+	require.NoError(t, err)
+	require.Equal(t, shell.Script{{Pipeline: []shell.Command{cmd("echo", "$HOME")}}}, script)
+
+	for input, expected := range map[string]string{
+		`echo ${HOME`:      "unterminated ${",
+		`echo ${}`:         "bad substitution",
+		`echo ${HOME:?x}`:  "unsupported substitution",
+		`echo "${HOME#/}"`: "unsupported substitution",
+	} {
+		_, err := shell.ParseEnv(input, lookup)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), expected)
+	}
+}
@@ -12,9 +12,13 @@ func Split(s string) []string {
 	for i, ch := range s {
 		switch ch {
 		case '{':
-			quote = ch
+			if quote == 0 {
+				quote = ch
+			}
 		case '}':
-			quote = 0
+			if quote == '{' {
+				quote = 0
+			}
 		case '\'', '"', '`':
 			if quote == ch {
 				out = append(out, s[start:i])
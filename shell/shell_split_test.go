@@ -45,6 +45,10 @@ func Test_ShellSplit(t *testing.T) {
 			input:    `{{some template 'stuff' }} should 'be ' "ver ba tim" `,
 			expected: List(`{{some template 'stuff' }}`, `should`, `be `, `ver ba tim`),
 		},
+		{
+			input:    `grep -v "{{ .Value }}" x`,
+			expected: List(`grep`, `-v`, `{{ .Value }}`, `x`),
+		},
 		{
 			input:    ` a 'very real"istic ' "te'st" with    lo\ts of  	'sp/\ces' ' her"e" ' `,
 			expected: List(`a`, `very real"istic `, `te'st`, `with`, `lo\ts`, `of`, `sp/\ces`, ` her"e" `),
@@ -0,0 +1,60 @@
+package stream
+
+import (
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/anchore/go-make/config"
+)
+
+// Group creates a writer that folds everything written through it under title in CI logs,
+// using the GitHub Actions ::group:: and ::endgroup:: workflow commands. The group is opened
+// on the first write, so output-less tasks don't leave empty folds behind, and closed by
+// Close; Close does not close w. When not running in CI, writes pass straight through to w.
+//
+// Groups cannot nest or interleave in a CI log, so callers producing output concurrently
+// should buffer each unit of work and write it through its own Group in one piece.
+func Group(w io.Writer, title string) io.WriteCloser {
+	return &groupWriter{
+		w:       w,
+		title:   strings.ReplaceAll(title, "\n", " "),
+		enabled: config.CI,
+	}
+}
+
+type groupWriter struct {
+	lock    sync.Mutex
+	w       io.Writer
+	title   string
+	enabled bool
+	opened  bool
+}
+
+func (g *groupWriter) Write(p []byte) (int, error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.enabled && !g.opened {
+		g.opened = true
+		if _, err := io.WriteString(g.w, "::group::"+g.title+"\n"); err != nil {
+			return 0, err
+		}
+	}
+	return g.w.Write(p)
+}
+
+// Close ends the group, if one was started
+func (g *groupWriter) Close() error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if !g.opened {
+		return nil
+	}
+	g.opened = false
+	_, err := io.WriteString(g.w, "::endgroup::\n")
+	return err
+}
+
+var _ io.WriteCloser = (*groupWriter)(nil)
@@ -0,0 +1,53 @@
+package stream
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// Lines creates a writer that calls fn once for every complete line written to it, without
+// the trailing newline (or carriage return). Partial lines are buffered until the rest of the
+// line arrives, or until Close is called, which flushes any remaining partial line.
+func Lines(fn func(line string)) io.WriteCloser {
+	return &lineWriter{fn: fn}
+}
+
+type lineWriter struct {
+	lock sync.Mutex
+	buf  []byte
+	fn   func(line string)
+}
+
+func (l *lineWriter) Write(p []byte) (int, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.buf = append(l.buf, p...)
+	start := 0
+	for {
+		idx := bytes.IndexByte(l.buf[start:], '\n')
+		if idx < 0 {
+			break
+		}
+		l.fn(string(bytes.TrimSuffix(l.buf[start:start+idx], []byte{'\r'})))
+		start += idx + 1
+	}
+	// keep only the trailing partial line
+	l.buf = append(l.buf[:0], l.buf[start:]...)
+	return len(p), nil
+}
+
+// Close flushes any buffered partial line
+func (l *lineWriter) Close() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if len(l.buf) > 0 {
+		l.fn(string(bytes.TrimSuffix(l.buf, []byte{'\r'})))
+		l.buf = nil
+	}
+	return nil
+}
+
+var _ io.WriteCloser = (*lineWriter)(nil)
@@ -0,0 +1,90 @@
+package stream
+
+import (
+	"io"
+	"reflect"
+	"sync"
+)
+
+var (
+	destinationLocksLock sync.Mutex
+	// destinationLocks holds one lock per destination writer while prefixed writers use it, so
+	// any number of prefixed writers sharing a destination (e.g. several tasks writing to
+	// os.Stderr) emit whole lines only
+	destinationLocks = map[io.Writer]*destinationLock{}
+)
+
+type destinationLock struct {
+	sync.Mutex
+	users int
+}
+
+// Prefix creates a writer that writes each line to w with prefix prepended, e.g. to label
+// output with the task that produced it. Lines are written to w whole, so prefixed writers
+// sharing a destination never interleave within a line. Empty lines are written without the
+// prefix. Close flushes any trailing partial line; it does not close w.
+func Prefix(w io.Writer, prefix string) io.WriteCloser {
+	lock := acquireDestinationLock(w)
+	lines := Lines(func(line string) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if line == "" {
+			_, _ = io.WriteString(w, "\n")
+			return
+		}
+		_, _ = io.WriteString(w, prefix+line+"\n")
+	})
+	return &prefixWriter{WriteCloser: lines, w: w}
+}
+
+type prefixWriter struct {
+	io.WriteCloser
+	w         io.Writer
+	closeOnce sync.Once
+}
+
+// Close flushes any trailing partial line and releases the lock of the destination
+func (p *prefixWriter) Close() error {
+	err := p.WriteCloser.Close()
+	p.closeOnce.Do(func() {
+		releaseDestinationLock(p.w)
+	})
+	return err
+}
+
+func acquireDestinationLock(w io.Writer) *destinationLock {
+	if !lockable(w) {
+		return &destinationLock{}
+	}
+	destinationLocksLock.Lock()
+	defer destinationLocksLock.Unlock()
+	lock := destinationLocks[w]
+	if lock == nil {
+		lock = &destinationLock{}
+		destinationLocks[w] = lock
+	}
+	lock.users++
+	return lock
+}
+
+func releaseDestinationLock(w io.Writer) {
+	if !lockable(w) {
+		return
+	}
+	destinationLocksLock.Lock()
+	defer destinationLocksLock.Unlock()
+	lock := destinationLocks[w]
+	if lock == nil {
+		return
+	}
+	lock.users--
+	if lock.users <= 0 {
+		delete(destinationLocks, w)
+	}
+}
+
+// lockable returns true for writers usable as map keys, other writers get a lock of their own
+func lockable(w io.Writer) bool {
+	return w != nil && reflect.TypeOf(w).Comparable()
+}
@@ -0,0 +1,127 @@
+package stream
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/require"
+)
+
+func Test_Lines(t *testing.T) {
+	var lines []string
+	w := Lines(func(line string) {
+		lines = append(lines, line)
+	})
+
+	_, err := w.Write([]byte("one\ntw"))
+	require.NoError(t, err)
+	require.EqualElements(t, []string{"one"}, lines)
+
+	_, err = w.Write([]byte("o\r\nthree\n\nfo"))
+	require.NoError(t, err)
+	require.EqualElements(t, []string{"one", "two", "three", ""}, lines)
+
+	require.NoError(t, w.Close())
+	require.Equal(t, []string{"one", "two", "three", "", "fo"}, lines)
+}
+
+func Test_Prefix(t *testing.T) {
+	buf := bytes.Buffer{}
+	w := Prefix(&buf, "[task] ")
+
+	_, err := w.Write([]byte("first line\nsecond "))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("line\n\npartial"))
+	require.NoError(t, err)
+	require.Equal(t, "[task] first line\n[task] second line\n\n", buf.String())
+
+	require.NoError(t, w.Close())
+	require.Equal(t, "[task] first line\n[task] second line\n\n[task] partial\n", buf.String())
+}
+
+func Test_PrefixConcurrentWritersDoNotInterleave(t *testing.T) {
+	buf := bytes.Buffer{}
+	wg := sync.WaitGroup{}
+	for i := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := Prefix(&buf, fmt.Sprintf("[%d] ", i))
+			for range 50 {
+				// write each line in two pieces to exercise partial-line buffering
+				_, _ = w.Write([]byte("some "))
+				_, _ = w.Write([]byte(fmt.Sprintf("output %d\n", i)))
+			}
+			require.NoError(t, w.Close())
+		}()
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Equal(t, 200, len(lines))
+	for _, line := range lines {
+		var n, m int
+		_, err := fmt.Sscanf(line, "[%d] some output %d", &n, &m)
+		require.NoError(t, err)
+		require.Equal(t, n, m)
+	}
+}
+
+func Test_PrefixReleasesDestinationLock(t *testing.T) {
+	buf := &bytes.Buffer{}
+	first := Prefix(buf, "[first] ")
+	second := Prefix(buf, "[second] ")
+	require.Equal(t, 2, destinationLocks[buf].users)
+
+	require.NoError(t, first.Close())
+	require.NoError(t, first.Close())
+	require.Equal(t, 1, destinationLocks[buf].users)
+
+	require.NoError(t, second.Close())
+	_, found := destinationLocks[buf]
+	require.False(t, found)
+}
+
+func Test_Group(t *testing.T) {
+	tests := []struct {
+		name     string
+		ci       bool
+		writes   []string
+		expected string
+	}{
+		{
+			name:     "not in CI passes through",
+			writes:   []string{"some output\n"},
+			expected: "some output\n",
+		},
+		{
+			name:     "in CI wraps output",
+			ci:       true,
+			writes:   []string{"some ", "output\n"},
+			expected: "::group::my-task\nsome output\n::endgroup::\n",
+		},
+		{
+			name:     "in CI no output no group",
+			ci:       true,
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.SetAndRestore(t, &config.CI, tt.ci)
+			buf := bytes.Buffer{}
+			w := Group(&buf, "my-task")
+			for _, s := range tt.writes {
+				_, err := w.Write([]byte(s))
+				require.NoError(t, err)
+			}
+			require.NoError(t, w.Close())
+			require.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
@@ -2,6 +2,7 @@ package gomake
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"slices"
 	"strings"
@@ -14,6 +15,7 @@ import (
 	"github.com/anchore/go-make/lang"
 	"github.com/anchore/go-make/log"
 	"github.com/anchore/go-make/run"
+	"github.com/anchore/go-make/stream"
 	"github.com/anchore/go-make/template"
 )
 
@@ -61,6 +63,39 @@ type Task struct {
 	// Run is the function that implements this task's behavior. If nil, the task acts as
 	// a label/phase that other tasks can depend on or hook into.
 	Run func()
+
+	// Matrix runs the task once per combination of values, e.g. to cross-compile or to test
+	// with several sets of build tags. Each combination is a separate task instance named after
+	// the task with its values in sorted key order, e.g. `make build[arm64,linux]` runs a single
+	// instance, while `make build` runs all of them. While an instance runs, its values are set
+	// as template variables, such as {{GOOS}}, and exported to all commands as environment
+	// variables, including GO* variables which are otherwise filtered.
+	//
+	// Example: Matrix: map[string][]string{"GOOS": {"linux", "darwin"}, "GOARCH": {"amd64", "arm64"}}
+	Matrix map[string][]string
+
+	// Parallel runs the Matrix instances concurrently, each in a separate process, rather than
+	// one after another. The output of each instance is shown when it completes.
+	Parallel bool
+
+	// Flags lists the command line flags this task accepts, such as "--json", in addition to
+	// the global --yes and -y. Flags are given as --name or --name=value and read with Flag;
+	// flags no task accepts are rejected.
+	//
+	// Example: Flags: List("--json")
+	Flags []string
+
+	// EnvPolicy sets which variables of the go-make process environment are passed to commands
+	// while this task runs, instead of run.DefaultEnvPolicy or the policy set with run.SetEnvPolicy.
+	//
+	// Example: EnvPolicy: &run.EnvPolicy{Keep: List("PATH", "HOME"), Clean: true}
+	EnvPolicy *run.EnvPolicy
+
+	// instances are the expanded Matrix instances of this task
+	instances []*Task
+
+	// values are the Matrix values of a task instance
+	values map[string]string
 }
 
 // DependsOn adds task names as dependencies, returning a new Task for method chaining.
@@ -118,9 +153,25 @@ func Makefile(tasks ...Task) {
 func runTaskFile(tasks ...Task) {
 	defer lang.HandleErrors()
 
-	file.Cd(template.Render(config.RootDir))
+	args, values, flags := parseArgs(os.Args[1:])
+	for key, value := range makeVariables(os.Getenv("MAKEFLAGS")) {
+		if _, ok := values[key]; !ok {
+			values[key] = value
+		}
+	}
+	t := taskRunner{buildDir: file.Cwd(), flags: flags, values: values}
+	commandLineFlags = flags
+	_, yes := flags["--yes"]
+	_, y := flags["-y"]
+	if yes || y {
+		config.AssumeYes = true
+	}
+	setArgValues(values)
 
-	t := taskRunner{}
+	// doctor diagnoses problems such as an unresolvable project root, so it runs regardless
+	if err := lang.Catch(func() { file.Cd(template.Render(config.RootDir)) }); err != nil && !slices.Equal(args, []string{"doctor"}) {
+		lang.Throw(err)
+	}
 
 	t.addTasks(tasks...)
 
@@ -152,6 +203,14 @@ func runTaskFile(tasks ...Task) {
 				Run("binny update")
 			},
 		},
+		&Task{
+			// runs after binny:update on dependencies:update, in the order tasks are defined
+			Name:   "binny:lock",
+			RunsOn: lang.List("dependencies:update"),
+			Run: func() {
+				binny.UpdateLock()
+			},
+		},
 		&Task{
 			Name: "binny:install",
 			Run: func() {
@@ -168,6 +227,11 @@ func runTaskFile(tasks ...Task) {
 				}
 			},
 		},
+		&Task{
+			Name:        "env",
+			Description: "print the environment commands receive, including .env files, with secrets masked",
+			Run:         func() { writeEnv(os.Stdout, run.Environ()) },
+		},
 		&Task{
 			Name: "dos2unix",
 			Run: func() {
@@ -183,26 +247,142 @@ func runTaskFile(tasks ...Task) {
 			Description: "run all tests",
 		},
 		&Task{
-			Name: "makefile",
-			Run:  t.Makefile,
+			Name:        "doctor",
+			Description: "diagnose problems with the local environment (--json for JSON output)",
+			Flags:       lang.List("--json"),
+			Run:         t.Doctor,
+		},
+		t.newTask(),
+		&Task{
+			Name:    "export:makefile",
+			Aliases: lang.List("makefile"),
+			Run:     func() { t.writeMakefile(os.Stdout) },
+		},
+		&Task{
+			Name: "export:justfile",
+			Run:  func() { t.writeJustfile(os.Stdout) },
+		},
+		&Task{
+			Name: "export:taskfile",
+			Run:  func() { t.writeTaskfile(os.Stdout) },
+		},
+		&Task{
+			Name: "export:vscode",
+			Run:  func() { t.writeVSCodeTasks(os.Stdout) },
+		},
+		&Task{
+			Name: "export:workflow",
+			Run:  func() { t.writeWorkflow(os.Stdout) },
 		},
 	)
 
-	args := os.Args[1:]
+	t.checkFlags()
 	if len(args) == 0 {
 		args = append(args, "help")
 	}
-	t.Run(args...)
+	t.Run(t.scaffoldArgs(args)...)
+}
+
+// globalFlags are the command line flags accepted regardless of the tasks, see Task.Flags
+var globalFlags = []string{"--yes", "-y"}
+
+// commandLineFlags are the flags go-make was run with, read with Flag
+var commandLineFlags = map[string]string{}
+
+// Flag returns the value of a command line flag, declared by a task in Task.Flags, and whether
+// it was given, e.g. "" for --json and "unit" for --suite=unit
+func Flag(name string) (value string, ok bool) {
+	value, ok = commandLineFlags[name]
+	return value, ok
+}
+
+func hasFlag(name string) bool {
+	_, ok := Flag(name)
+	return ok
+}
+
+// parseArgs separates command line flags, arguments starting with "-", and KEY=VALUE arguments,
+// which set template variables, from task names
+func parseArgs(args []string) (tasks []string, values map[string]string, flags map[string]string) {
+	values = map[string]string{}
+	flags = map[string]string{}
+	for _, arg := range args {
+		if key, value, ok := strings.Cut(arg, "="); ok && key != "" && !strings.HasPrefix(arg, "-") {
+			values[key] = value
+			continue
+		}
+		if !strings.HasPrefix(arg, "-") {
+			tasks = append(tasks, arg)
+			continue
+		}
+		name, value, _ := strings.Cut(arg, "=")
+		flags[name] = value
+	}
+	return tasks, values, flags
+}
+
+// makeVariables returns the KEY=VALUE variables given on the make command line, which make
+// passes in MAKEFLAGS following "--", e.g. "s -- Name=billing Owner=a\ team", rather than as
+// arguments to the recipe
+func makeVariables(makeflags string) map[string]string {
+	values := map[string]string{}
+	_, vars, ok := strings.Cut(" "+makeflags, " -- ")
+	if !ok {
+		return values
+	}
+	// spaces within values are escaped with a backslash
+	var words []string
+	word := strings.Builder{}
+	for i := 0; i < len(vars); i++ {
+		switch {
+		case vars[i] == '\\' && i+1 < len(vars) && vars[i+1] == ' ':
+			word.WriteByte(' ')
+			i++
+		case vars[i] == ' ':
+			words = append(words, word.String())
+			word.Reset()
+		default:
+			word.WriteByte(vars[i])
+		}
+	}
+	words = append(words, word.String())
+	for _, w := range words {
+		if key, value, ok := strings.Cut(w, "="); ok {
+			// make also accepts KEY:=VALUE and KEY::=VALUE
+			if key = strings.TrimRight(key, ":"); key != "" {
+				values[key] = value
+			}
+		}
+	}
+	return values
+}
+
+// checkFlags panics when a command line flag is not accepted by any task, see Task.Flags
+func (t *taskRunner) checkFlags() {
+	accepted := slices.Clone(globalFlags)
+	for _, tsk := range t.tasks {
+		accepted = append(accepted, tsk.Flags...)
+	}
+	for _, name := range slices.Sorted(maps.Keys(t.flags)) {
+		if !slices.Contains(accepted, name) {
+			slices.Sort(accepted)
+			panic(fmt.Errorf("unknown flag: %s, supported flags: %s", color.Bold(name), strings.Join(slices.Compact(accepted), ", ")))
+		}
+	}
 }
 
 type taskRunner struct {
-	tasks []*Task
-	run   set[*Task]
+	tasks    []*Task
+	run      set[*Task]
+	flags    map[string]string // command line flags, see Flag
+	values   map[string]string // KEY=VALUE command line arguments
+	buildDir string            // the directory of the build script, the working directory at startup
 }
 
 func (t *taskRunner) addTasks(tasks ...Task) {
 	for _, task := range tasks {
 		t.tasks = append(t.tasks, &task)
+		t.tasks = append(t.tasks, expandMatrix(&task)...)
 		t.addTasks(task.Tasks...)
 	}
 }
@@ -223,10 +403,6 @@ func (t *taskRunner) Run(args ...string) {
 }
 
 func (t *taskRunner) runTask(name string) {
-	// each task is going to set the log prefix
-	origLogPrefix := log.Prefix
-	defer func() { log.Prefix = origLogPrefix }()
-
 	tasks := t.findByName(name)
 	if len(tasks) == 0 {
 		panic(fmt.Errorf("no tasks named: %s", color.Bold(color.Underline(name))))
@@ -238,19 +414,42 @@ func (t *taskRunner) runTask(name string) {
 			continue
 		}
 		t.run.Add(tsk)
-		for _, dep := range t.findByLabel(tsk.Name) {
-			t.runTask(dep.Name)
+		if !config.NoDeps {
+			for _, dep := range t.findByLabel(tsk.Name) {
+				t.runTask(dep.Name)
+			}
+			for _, dep := range tsk.Dependencies {
+				t.runTask(dep)
+			}
 		}
-		for _, dep := range tsk.Dependencies {
-			t.runTask(dep)
+
+		switch {
+		case len(tsk.instances) > 0:
+			t.runMatrix(tsk)
+		case tsk.Run != nil:
+			runWithTaskOutput(tsk)
 		}
+	}
+}
 
-		log.Prefix = fmt.Sprintf(color.Green("[%s] "), tsk.Name)
+// runWithTaskOutput executes the task's Run function with all log and command output labelled
+// by the task name and, when running in CI, folded into a group named after the task
+func runWithTaskOutput(tsk *Task) {
+	group := stream.Group(log.Writer(run.Context()), tsk.Name)
+	out := stream.Prefix(group, fmt.Sprintf(color.Green("[%s] "), tsk.Name))
+	restoreOutput := run.SetOutput(out)
+	defer func() {
+		restoreOutput()
+		lang.Close(out, tsk.Name)
+		lang.Close(group, tsk.Name)
+	}()
 
-		if tsk.Run != nil {
-			tsk.Run()
-		}
+	defer run.SetTaskName(tsk.Name)()
+	if tsk.EnvPolicy != nil {
+		defer run.SetEnvPolicy(*tsk.EnvPolicy)()
 	}
+
+	tsk.Run()
 }
 
 func (t *taskRunner) findByName(name string) []*Task {
@@ -272,18 +471,3 @@ func (t *taskRunner) findByLabel(name string) []*Task {
 	}
 	return out
 }
-
-func (t *taskRunner) Makefile() {
-	buildCmdDir := strings.TrimLeft(strings.TrimPrefix(file.Cwd(), RootDir()), `\/`)
-	for _, t := range t.tasks {
-		for _, name := range append([]string{t.Name}, t.Aliases...) {
-			fmt.Printf(".PHONY: %s\n", name)
-			fmt.Printf("%s:\n", name)
-			fmt.Printf("\t@go run -C %s . %s\n", buildCmdDir, name)
-		}
-	}
-	// catch-all, could be the entire script except for FreeBSD
-	fmt.Printf(".PHONY: *\n")
-	fmt.Printf(".DEFAULT:\n")
-	fmt.Printf("\t@go run -C %s . $@\n", buildCmdDir)
-}
@@ -5,8 +5,12 @@ import (
 
 	. "github.com/anchore/go-make"
 	"github.com/anchore/go-make/binny"
+	"github.com/anchore/go-make/config"
 	"github.com/anchore/go-make/file"
+	"github.com/anchore/go-make/github"
 	"github.com/anchore/go-make/internal/ci"
+	"github.com/anchore/go-make/lang"
+	"github.com/anchore/go-make/log"
 	"github.com/anchore/go-make/run"
 	"github.com/anchore/go-make/tasks/release"
 )
@@ -34,9 +38,11 @@ func ReleaseTask() Task {
 	return Task{
 		Name:         "ci:release",
 		Aliases:      []string{"ci-release"},
-		Dependencies: Deps("release:dependencies"),
+		Dependencies: Deps("release:audit", "release:dependencies"),
 		Run: func() {
 			file.Require(configName)
+			// record which tools ran during the release, including when it fails
+			defer uploadAuditLog()
 
 			tagName := ci.ReleaseTagInput()
 
@@ -47,7 +53,25 @@ func ReleaseTask() Task {
 
 			Run(`goreleaser release --clean --release-notes`, run.Args(changelogFile))
 		},
-		Tasks: releaseDependencyTasks("quill", "syft", "cosign"),
+		Tasks: append(releaseDependencyTasks("quill", "syft", "cosign"), Task{
+			// the uploaded audit log records only the commands of this release
+			Name: "release:audit",
+			Run:  run.RotateAuditLog,
+		}),
+	}
+}
+
+// uploadAuditLog best-effort uploads the audit log of executed commands as a GitHub Actions
+// artifact. Errors are logged but don't fail the release.
+func uploadAuditLog() {
+	if !config.CI {
+		return
+	}
+	err := lang.Catch(func() {
+		lang.Return(github.NewClient().UploadAuditLog())
+	})
+	if err != nil {
+		log.Warn("error uploading audit log: %v", err)
 	}
 }
 
@@ -1,6 +1,8 @@
 package gotest
 
 import (
+	"embed"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -20,6 +22,7 @@ import (
 //   - fixtures:clean: cleans all fixture caches (runs on "clean")
 //   - fixtures:directories: lists all fixture directories
 //   - fixtures:fingerprint: outputs a hash of all fixture files for caching
+//   - new:fixture: creates a fixture directory, see FixtureScaffold
 func FixtureTasks() Task {
 	return Task{
 		Name:        "fixtures",
@@ -77,6 +80,22 @@ func FixtureTasks() Task {
 					lang.Return(os.Stdout.WriteString(file.Fingerprint(file.JoinPaths(RootDir(), "**/{test-fixtures,testdata}/*"))))
 				},
 			},
+			FixtureScaffold(),
 		},
 	}
 }
+
+//go:embed all:scaffold/fixture
+var fixtureScaffold embed.FS
+
+// FixtureScaffold returns the new:fixture task, creating a fixture directory <Dir>/testdata/<Name>
+// with a Makefile building it, along with the testdata Makefile building every fixture directory
+// when it doesn't exist yet, e.g. `make new fixture Dir=pkg/parser Name=image`
+func FixtureScaffold() Task {
+	return Scaffold{
+		Name:        "fixture",
+		Description: "create a test fixture directory: <Dir>/testdata/<Name>",
+		Files:       lang.Return(fs.Sub(fixtureScaffold, "scaffold/fixture")),
+		Values:      lang.List("Dir", "Name"),
+	}.Task()
+}
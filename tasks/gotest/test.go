@@ -60,7 +60,7 @@ func runTests(cfg *Config) {
 	defer cleanup()
 
 	args := buildTestArgs(cfg, coverageFile)
-	Run("go", run.Args(args...), run.Stdout(os.Stderr), run.Env("GODEBUG", "dontfreezetheworld=1"))
+	Run("go", run.Args(args...), run.Stdout(log.CurrentOutput()), run.Env("GODEBUG", "dontfreezetheworld=1"))
 	Log("Done running %s tests in %v", cfg.Name, time.Since(start))
 
 	if coverageFile == "" {
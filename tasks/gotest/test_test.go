@@ -1,10 +1,14 @@
 package gotest_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/anchore/go-make/file"
 	"github.com/anchore/go-make/require"
 	"github.com/anchore/go-make/tasks/gotest"
+	"github.com/anchore/go-make/template"
 )
 
 func Test_Task(t *testing.T) {
@@ -29,3 +33,23 @@ func Test_Task(t *testing.T) {
 	gotest.CoverageThreshold(75.5)(&cfg)
 	require.Equal(t, 75.5, cfg.CoverageThreshold)
 }
+
+func Test_FixtureScaffold(t *testing.T) {
+	require.SetAndRestore(t, &template.Globals, map[string]any{"Dir": "pkg/parser", "Name": "image"})
+
+	file.InDir(t.TempDir(), func() {
+		gotest.FixtureScaffold().Run()
+
+		makefile, err := os.ReadFile(filepath.Join("pkg", "parser", "testdata", "Makefile"))
+		require.NoError(t, err)
+		require.Contains(t, string(makefile), "$(MAKE) -C $@")
+
+		makefile, err = os.ReadFile(filepath.Join("pkg", "parser", "testdata", "image", "Makefile"))
+		require.NoError(t, err)
+		require.Contains(t, string(makefile), "out/image.txt:\n\tmkdir -p out\n")
+
+		gitignore, err := os.ReadFile(filepath.Join("pkg", "parser", "testdata", "image", ".gitignore"))
+		require.NoError(t, err)
+		require.Equal(t, "/out/\n", string(gitignore))
+	})
+}
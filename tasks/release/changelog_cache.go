@@ -19,6 +19,7 @@ import (
 	"github.com/anchore/go-make/file"
 	"github.com/anchore/go-make/gomod"
 	"github.com/anchore/go-make/log"
+	"github.com/anchore/go-make/redact"
 	"github.com/anchore/go-make/run"
 )
 
@@ -438,10 +439,12 @@ func projectName() (name string, err error) {
 }
 
 func githubToken() string {
-	if t := strings.TrimSpace(os.Getenv("GITHUB_TOKEN")); t != "" {
-		return t
+	t := strings.TrimSpace(os.Getenv("GITHUB_TOKEN"))
+	if t == "" {
+		t = strings.TrimSpace(Run("gh auth token", run.NoFail()))
 	}
-	return strings.TrimSpace(Run("gh auth token", run.NoFail()))
+	redact.Register(t)
+	return t
 }
 
 func basicAuth(user, pass string) string {
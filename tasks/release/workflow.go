@@ -5,14 +5,18 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
+	"golang.org/x/mod/semver"
+
 	. "github.com/anchore/go-make"
 	"github.com/anchore/go-make/file"
 	"github.com/anchore/go-make/gomod"
 	"github.com/anchore/go-make/lang"
 	"github.com/anchore/go-make/log"
+	"github.com/anchore/go-make/redact"
 	"github.com/anchore/go-make/run"
 	"github.com/anchore/go-make/script"
 )
@@ -23,8 +27,9 @@ const (
 )
 
 // WorkflowReleaseTask creates a task that triggers a GitHub Actions release workflow.
-// It generates a changelog, prompts for confirmation, then triggers the release.yaml
-// workflow with the version from the VERSION file.
+// It generates a changelog, prompts for the version to release, offering the changelog version
+// and the patch, minor and major bumps of the latest tag, and after confirmation, which is never
+// assumed with --yes, triggers the release.yaml workflow.
 func WorkflowReleaseTask() Task {
 	return Task{
 		Name:        "release",
@@ -32,7 +37,7 @@ func WorkflowReleaseTask() Task {
 		Run: func() {
 			file.Require(filepath.Join(workflowsPath, releaseWorkflowName))
 
-			Run("gh auth status", run.Stdout(os.Stderr))
+			Run("gh auth status", run.Stdout(log.CurrentOutput()))
 
 			m := gomod.Read()
 			if m != nil {
@@ -51,6 +56,7 @@ func WorkflowReleaseTask() Task {
 				githubToken = Run("gh auth token")
 				lang.Throw(os.Setenv("GITHUB_TOKEN", githubToken))
 			}
+			redact.Register(githubToken)
 
 			// ensure we have up-to-date git tags
 			Run("git fetch --tags")
@@ -64,8 +70,14 @@ func WorkflowReleaseTask() Task {
 				os.Exit(1)
 			}
 
-			// confirm if we should release
-			script.Confirm("Do you want to trigger a release for version '%s'?", nextVersion)
+			// choose the version to release, suggesting the changelog version first
+			latestVersion := Run("git describe --tags --abbrev=0", run.NoFail())
+			nextVersion = script.Select("Which version do you want to release?", versionChoices(nextVersion, latestVersion))
+
+			// always confirm, even with --yes, as this starts a production release
+			if !script.YesNo(fmt.Sprintf("Release %s?", nextVersion), script.RequireAnswer()) {
+				panic(fmt.Errorf("CANCELLED: release %s", nextVersion))
+			}
 
 			// trigger release
 			log.Info("Kicking off release for %s", nextVersion)
@@ -79,3 +91,24 @@ func WorkflowReleaseTask() Task {
 		},
 	}
 }
+
+// versionChoices returns the next version followed by the patch, minor and major bumps of the
+// latest version, without duplicates. Bumps are omitted when latest is not a semantic version.
+func versionChoices(next, latest string) []string {
+	choices := []string{next}
+	if !semver.IsValid(latest) {
+		return choices
+	}
+	var major, minor, patch int
+	_, _ = fmt.Sscanf(semver.Canonical(latest), "v%d.%d.%d", &major, &minor, &patch)
+	for _, bump := range []string{
+		fmt.Sprintf("v%d.%d.%d", major, minor, patch+1),
+		fmt.Sprintf("v%d.%d.0", major, minor+1),
+		fmt.Sprintf("v%d.0.0", major+1),
+	} {
+		if !slices.Contains(choices, bump) {
+			choices = append(choices, bump)
+		}
+	}
+	return choices
+}
@@ -0,0 +1,46 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/anchore/go-make/require"
+)
+
+func Test_versionChoices(t *testing.T) {
+	tests := []struct {
+		name   string
+		next   string
+		latest string
+		want   []string
+	}{
+		{
+			name:   "next is a minor bump",
+			next:   "v1.3.0",
+			latest: "v1.2.3",
+			want:   []string{"v1.3.0", "v1.2.4", "v2.0.0"},
+		},
+		{
+			name:   "next is unrelated",
+			next:   "v5.0.0",
+			latest: "v0.1.0",
+			want:   []string{"v5.0.0", "v0.1.1", "v0.2.0", "v1.0.0"},
+		},
+		{
+			name:   "prerelease latest",
+			next:   "v1.0.0",
+			latest: "v1.0.0-rc.1",
+			want:   []string{"v1.0.0", "v1.0.1", "v1.1.0", "v2.0.0"},
+		},
+		{
+			name:   "no tags",
+			next:   "v0.1.0",
+			latest: "",
+			want:   []string{"v0.1.0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, versionChoices(tt.next, tt.latest))
+		})
+	}
+}
@@ -0,0 +1,43 @@
+// Package scaffold provides scaffolds for common Go project files, run with `make new <name>`.
+package scaffold
+
+import (
+	"embed"
+	"io/fs"
+
+	. "github.com/anchore/go-make"
+	"github.com/anchore/go-make/lang"
+)
+
+//go:embed all:files
+var files embed.FS
+
+// Tasks returns all scaffold tasks
+func Tasks() Task {
+	return Task{
+		Tasks: []Task{
+			Cmd(),     // `make new cmd Name=<name>` to create cmd/<name>/main.go
+			Package(), // `make new package Path=<path>` to create a package with a test
+		},
+	}
+}
+
+// Cmd returns the new:cmd task, creating a command at cmd/<Name>/main.go
+func Cmd() Task {
+	return Scaffold{
+		Name:        "cmd",
+		Description: "create a new command: cmd/<Name>/main.go",
+		Files:       lang.Return(fs.Sub(files, "files/cmd")),
+		Values:      lang.List("Name"),
+	}.Task()
+}
+
+// Package returns the new:package task, creating a package with a test at <Path>
+func Package() Task {
+	return Scaffold{
+		Name:        "package",
+		Description: "create a new package with a test: <Path>/<name>.go",
+		Files:       lang.Return(fs.Sub(files, "files/package")),
+		Values:      lang.List("Path"),
+	}.Task()
+}
@@ -0,0 +1,34 @@
+package scaffold
+
+import (
+	"go/format"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/anchore/go-make/file"
+	"github.com/anchore/go-make/require"
+	"github.com/anchore/go-make/template"
+)
+
+func Test_scaffolds(t *testing.T) {
+	require.SetAndRestore(t, &template.Globals, map[string]any{"Name": "server", "Path": "internal/parser"})
+
+	file.InDir(t.TempDir(), func() {
+		Cmd().Run()
+		Package().Run()
+
+		for _, path := range []string{
+			"cmd/server/main.go",
+			"internal/parser/parser.go",
+			"internal/parser/parser_test.go",
+		} {
+			contents, err := os.ReadFile(filepath.FromSlash(path))
+			require.NoError(t, err)
+			// the rendered files are valid, formatted Go
+			formatted, err := format.Source(contents)
+			require.NoError(t, err)
+			require.Equal(t, string(formatted), string(contents))
+		}
+	})
+}
@@ -2,10 +2,14 @@ package gomake
 
 import (
 	"bytes"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/anchore/go-make/require"
 	"github.com/anchore/go-make/run"
+	"github.com/anchore/go-make/stream"
+	"github.com/anchore/go-make/template"
 )
 
 func Test_taskAliasResolution(t *testing.T) {
@@ -43,3 +47,64 @@ func Test_errorsIncludeStackTrace(t *testing.T) {
 	// includes a link to the file:line in the script where the error occurred -- IMPORTANT!
 	require.Contains(t, stderr.String(), "main.go:20")
 }
+
+func Test_matrixExpansion(t *testing.T) {
+	var ran []string
+	r := taskRunner{}
+	r.addTasks(Task{
+		Name: "build",
+		Matrix: map[string][]string{
+			"GOOS":   {"linux", "darwin"},
+			"GOARCH": {"amd64", "arm64"},
+		},
+		Run: func() {
+			ran = append(ran, template.Render("{{GOOS}}/{{GOARCH}}"))
+		},
+	})
+
+	// instances are named with values in sorted key order
+	var names []string
+	for _, task := range r.tasks[1:] {
+		names = append(names, task.Name)
+	}
+	require.EqualElements(t, []string{
+		"build[amd64,linux]",
+		"build[amd64,darwin]",
+		"build[arm64,linux]",
+		"build[arm64,darwin]",
+	}, names)
+
+	// a single instance can be run by name
+	r.Run("build[arm64,darwin]")
+	require.EqualElements(t, []string{"darwin/arm64"}, ran)
+
+	// running the task runs all instances, with matrix values removed afterward
+	ran = nil
+	r.Run("build")
+	require.EqualElements(t, []string{"linux/amd64", "darwin/amd64", "linux/arm64", "darwin/arm64"}, ran)
+	_, defined := template.Globals["GOOS"]
+	require.False(t, defined)
+}
+
+func Test_matrixParallel(t *testing.T) {
+	// instance output is relayed through the log output, check everything
+	buf := bytes.Buffer{}
+	output := stream.Tee(&buf)
+	_, err := run.Command("go", run.Args("run", "./testdata/matrix-example", "matrix-example"), run.Stdout(output), run.Stderr(output))
+	require.NoError(t, err)
+
+	// dependencies run once, in the parent process
+	require.Equal(t, 1, strings.Count(buf.String(), "setup ran"))
+
+	// each instance runs with its own values, exported to commands
+	for _, goos := range []string{"linux", "darwin"} {
+		for _, goarch := range []string{"amd64", "arm64"} {
+			require.Contains(t, buf.String(), fmt.Sprintf("instance %s/%s env %q", goos, goarch, goos+"\n"+goarch))
+		}
+	}
+
+	stderr := bytes.Buffer{}
+	_, err = run.Command("go", run.Args("run", "./testdata/matrix-example", "matrix-failure"), run.Stderr(&stderr))
+	require.Error(t, err)
+	require.Contains(t, stderr.String(), "failed: matrix-failure[fail]")
+}
@@ -0,0 +1,11 @@
+package template
+
+// MaxParsed is the number of parsed templates cached
+var MaxParsed = &maxParsed
+
+// ParsedLen returns the number of parsed templates cached
+func ParsedLen() int {
+	parsedLock.Lock()
+	defer parsedLock.Unlock()
+	return parsedOrder.Len()
+}
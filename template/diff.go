@@ -0,0 +1,108 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around changes
+const diffContext = 3
+
+// unifiedDiff returns a unified diff of the lines of before and after, for the file at path
+func unifiedDiff(path, before, after string) string {
+	a := splitLines(before)
+	b := splitLines(after)
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type line struct {
+		op   byte
+		text string
+		a, b int // line indexes in a and b before this line
+	}
+	var lines []line
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, line{' ', a[i], i, j})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, line{'-', a[i], i, j})
+			i++
+		default:
+			lines = append(lines, line{'+', b[j], i, j})
+			j++
+		}
+	}
+
+	out := strings.Builder{}
+	out.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", path, path))
+	for start := 0; start < len(lines); {
+		// find the next change, and the end of the hunk including it and any changes nearby
+		first := start
+		for first < len(lines) && lines[first].op == ' ' {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+		from := max(first-diffContext, start)
+		to, unchanged := first, 0
+		for k := first; k < len(lines) && unchanged <= 2*diffContext; k++ {
+			if lines[k].op == ' ' {
+				unchanged++
+				continue
+			}
+			to, unchanged = k, 0
+		}
+		to = min(to+diffContext+1, len(lines))
+
+		var aLen, bLen int
+		for _, l := range lines[from:to] {
+			if l.op != '+' {
+				aLen++
+			}
+			if l.op != '-' {
+				bLen++
+			}
+		}
+		out.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(lines[from].a, aLen), hunkRange(lines[from].b, bLen)))
+		for _, l := range lines[from:to] {
+			out.WriteByte(l.op)
+			out.WriteString(l.text)
+			out.WriteByte('\n')
+		}
+		start = to
+	}
+	return out.String()
+}
+
+// hunkRange formats the start line and length of a hunk, with 1-based line numbers
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,207 @@
+package template
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// WriteMode is how RenderFile and RenderDir handle destination files that already exist
+type WriteMode int
+
+const (
+	// SkipExisting keeps existing files unchanged, only writing new files. This is the default.
+	SkipExisting WriteMode = iota
+
+	// Overwrite replaces existing files with the rendered contents
+	Overwrite
+
+	// Diff writes nothing, reporting the files that would be created or updated along with
+	// a diff of the changes to existing files
+	Diff
+)
+
+// FileStatus is the outcome of rendering a file with RenderFile or RenderDir
+type FileStatus string
+
+const (
+	// Created is a new file, not written with Diff
+	Created FileStatus = "created"
+	// Updated is an existing file with new contents, not written with Diff
+	Updated FileStatus = "updated"
+	// Unchanged is an existing file with the same contents as rendered
+	Unchanged FileStatus = "unchanged"
+	// Skipped is an existing file with different contents, left as-is with SkipExisting
+	Skipped FileStatus = "skipped"
+)
+
+// RenderedFile is a file rendered by RenderFile or RenderDir
+type RenderedFile struct {
+	// Path is the destination file
+	Path string
+
+	// Status is the outcome of rendering the file
+	Status FileStatus
+
+	// Diff shows the changes to an existing file with the Diff mode, as a unified diff
+	Diff string
+}
+
+// FileOption configures RenderFile and RenderDir
+type FileOption func(*fileConfig)
+
+type fileConfig struct {
+	fsys fs.FS
+	mode WriteMode
+}
+
+// FS reads the source files from fsys, such as an embed.FS, rather than the file system
+func FS(fsys fs.FS) FileOption {
+	return func(c *fileConfig) {
+		c.fsys = fsys
+	}
+}
+
+// Existing sets how existing destination files are handled, SkipExisting by default
+func Existing(mode WriteMode) FileOption {
+	return func(c *fileConfig) {
+		c.mode = mode
+	}
+}
+
+// RenderFile renders the template file src with data, as Render does, writing the result to
+// dst, which is itself a template. The destination has the permissions of the source, writable
+// by the owner, and missing parent directories are created. Errors panic.
+//
+// Example:
+//
+//	template.RenderFile("templates/config.yaml", "{{ToolDir}}/config.yaml", map[string]any{"Debug": true})
+func RenderFile(src, dst string, data map[string]any, opts ...FileOption) RenderedFile {
+	cfg := newFileConfig(opts)
+	return cfg.writeFile(src, Render(dst, data), data, true)
+}
+
+// RenderDir renders all files of srcDir to dstDir, keeping the directory structure. The
+// destination directory and every file and directory name are templates rendered with data,
+// such as cmd/{{.Name}}/main.go. Files ending in .tmpl are rendered as templates, and written
+// without the .tmpl suffix, so Go sources can be embedded; other files are copied as-is.
+// Returns the rendered files in order. Errors panic.
+//
+// Example:
+//
+//	//go:embed all:scaffold
+//	var scaffold embed.FS
+//
+//	template.RenderDir("scaffold", "cmd/{{.Name}}", map[string]any{"Name": "app"}, template.FS(scaffold))
+func RenderDir(srcDir, dstDir string, data map[string]any, opts ...FileOption) []RenderedFile {
+	cfg := newFileConfig(opts)
+	dstDir = Render(dstDir, data)
+	root := fsPath(srcDir)
+	var out []RenderedFile
+	err := fs.WalkDir(cfg.fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel := p
+		if root != "." {
+			rel = strings.TrimPrefix(p, root+"/")
+		}
+		render := strings.HasSuffix(rel, ".tmpl")
+		dst := filepath.Join(dstDir, filepath.FromSlash(Render(strings.TrimSuffix(rel, ".tmpl"), data)))
+		out = append(out, cfg.writeFile(p, dst, data, render))
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	return out
+}
+
+func newFileConfig(opts []FileOption) *fileConfig {
+	cfg := &fileConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.fsys == nil {
+		cfg.fsys = osFS{}
+	}
+	return cfg
+}
+
+// fsPath returns the slash-separated path of a source file in the file system
+func fsPath(p string) string {
+	return path.Clean(filepath.ToSlash(p))
+}
+
+// writeFile renders or copies src to dst according to the write mode
+func (c *fileConfig) writeFile(src, dst string, data map[string]any, render bool) RenderedFile {
+	src = fsPath(src)
+	contents, err := fs.ReadFile(c.fsys, src)
+	if err != nil {
+		panic(err)
+	}
+	info, err := fs.Stat(c.fsys, src)
+	if err != nil {
+		panic(err)
+	}
+	if render {
+		contents = []byte(Render(string(contents), data))
+	}
+
+	result := RenderedFile{Path: dst, Status: Created}
+	existing, err := os.ReadFile(dst)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		panic(err)
+	case bytes.Equal(existing, contents):
+		result.Status = Unchanged
+		return result
+	case c.mode == SkipExisting:
+		result.Status = Skipped
+		return result
+	default:
+		result.Status = Updated
+		if c.mode == Diff {
+			result.Diff = unifiedDiff(dst, string(existing), string(contents))
+		}
+	}
+	if c.mode == Diff {
+		return result
+	}
+
+	if err = os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		panic(err)
+	}
+	// embedded files are read-only
+	perm := info.Mode().Perm() | 0o200
+	if err = os.WriteFile(dst, contents, perm); err != nil {
+		panic(err)
+	}
+	// WriteFile keeps the permissions of existing files
+	if err = os.Chmod(dst, perm); err != nil {
+		panic(fmt.Errorf("unable to set permissions of %s: %w", dst, err))
+	}
+	return result
+}
+
+// osFS reads files from the operating system by path, which may be absolute or relative to the
+// working directory, unlike os.DirFS
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+func (osFS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+func (osFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
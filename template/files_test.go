@@ -0,0 +1,99 @@
+package template_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"testing/fstest"
+
+	"github.com/anchore/go-make/require"
+	"github.com/anchore/go-make/template"
+)
+
+func Test_RenderFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.txt")
+	require.NoError(t, os.WriteFile(src, []byte("hello {{.Name}}\n"), 0o755))
+
+	result := template.RenderFile(src, filepath.Join(dir, "out", "{{.Name}}.txt"), map[string]any{"Name": "world"})
+	dst := filepath.Join(dir, "out", "world.txt")
+	require.Equal(t, template.RenderedFile{Path: dst, Status: template.Created}, result)
+	requireFile(t, dst, "hello world\n")
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(dst)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+	}
+
+	// existing files are kept by default
+	result = template.RenderFile(src, dst, map[string]any{"Name": "again"})
+	require.Equal(t, template.Skipped, result.Status)
+	requireFile(t, dst, "hello world\n")
+
+	result = template.RenderFile(src, dst, map[string]any{"Name": "world"})
+	require.Equal(t, template.Unchanged, result.Status)
+
+	result = template.RenderFile(src, dst, map[string]any{"Name": "again"}, template.Existing(template.Diff))
+	require.Equal(t, template.Updated, result.Status)
+	require.Equal(t, "--- "+dst+"\n+++ "+dst+"\n@@ -1,1 +1,1 @@\n-hello world\n+hello again\n", result.Diff)
+	requireFile(t, dst, "hello world\n")
+
+	result = template.RenderFile(src, dst, map[string]any{"Name": "again"}, template.Existing(template.Overwrite))
+	require.Equal(t, template.Updated, result.Status)
+	requireFile(t, dst, "hello again\n")
+}
+
+func Test_RenderDir(t *testing.T) {
+	fsys := fstest.MapFS{
+		"scaffold/{{.Name}}/main.go.tmpl": {Data: []byte("package {{.Name}}\n"), Mode: 0o444},
+		"scaffold/{{.Name}}/README.md":    {Data: []byte("# {{.Name}} is copied as-is\n"), Mode: 0o444},
+		"scaffold/.gitignore":             {Data: []byte("/bin/\n"), Mode: 0o444},
+		"other/file.txt":                  {Data: []byte("not included\n")},
+	}
+	dir := t.TempDir()
+	data := map[string]any{"Name": "app"}
+
+	results := template.RenderDir("scaffold", filepath.Join(dir, "{{.Name}}-root"), data, template.FS(fsys))
+	root := filepath.Join(dir, "app-root")
+	require.Equal(t, []template.RenderedFile{
+		{Path: filepath.Join(root, ".gitignore"), Status: template.Created},
+		{Path: filepath.Join(root, "app", "README.md"), Status: template.Created},
+		{Path: filepath.Join(root, "app", "main.go"), Status: template.Created},
+	}, results)
+	requireFile(t, filepath.Join(root, "app", "main.go"), "package app\n")
+	requireFile(t, filepath.Join(root, "app", "README.md"), "# {{.Name}} is copied as-is\n")
+	requireFile(t, filepath.Join(root, ".gitignore"), "/bin/\n")
+
+	// embedded files are read-only, written files are writable
+	require.NoError(t, os.WriteFile(filepath.Join(root, "app", "main.go"), []byte("package changed\n"), 0o644))
+
+	results = template.RenderDir("scaffold", root, nil, template.FS(fsys), template.Existing(template.Diff))
+	require.Equal(t, template.Unchanged, results[0].Status)
+	require.Equal(t, template.Created, results[1].Status)
+	require.Equal(t, filepath.Join(root, "<no value>", "README.md"), results[1].Path)
+	_, err := os.Stat(filepath.Join(root, "<no value>"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func Test_RenderDir_diff(t *testing.T) {
+	before := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	after := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n"
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "numbers.txt")
+	require.NoError(t, os.WriteFile(dst, []byte(before), 0o600))
+	fsys := fstest.MapFS{"numbers.txt": {Data: []byte(after)}}
+
+	results := template.RenderDir(".", dir, nil, template.FS(fsys), template.Existing(template.Diff))
+	require.Equal(t, 1, len(results))
+	require.Equal(t, "--- "+dst+"\n+++ "+dst+"\n"+
+		"@@ -1,6 +1,6 @@\n 1\n 2\n-3\n+three\n 4\n 5\n 6\n"+
+		"@@ -10,3 +10,4 @@\n 10\n 11\n 12\n+13\n", results[0].Diff)
+}
+
+func requireFile(t *testing.T, path, expected string) {
+	t.Helper()
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(contents))
+}
@@ -0,0 +1,130 @@
+package template
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"golang.org/x/mod/semver"
+)
+
+// Funcs returns the functions available in all templates, in addition to Globals:
+//   - env NAME: the value of an environment variable, empty when unset
+//   - default DEFAULT VALUE: VALUE, or DEFAULT when VALUE is empty
+//   - upper STRING, lower STRING: the string in upper or lower case
+//   - trimPrefix PREFIX STRING: the string without the leading prefix
+//   - sha256 STRING: the hex encoded SHA-256 of the string
+//   - semverBump major|minor|patch VERSION: the next version, e.g. v1.3.0 for minor v1.2.3
+//   - gitDescribe: the most recent tag, with the commits since (provided by the git package)
+//   - now [LAYOUT]: the current UTC time, formatted with the time layout, RFC 3339 by default
+//   - join SEPARATOR LIST: the elements of the list joined with the separator
+//   - fromJSON STRING, fromYAML STRING: the decoded value, such as a map
+//   - readFile PATH: the contents of the file
+//   - base PATH: the last element of a slash-separated path, such as a package name
+//
+// Arguments are ordered so the value can be piped, as in {{ .Version | trimPrefix "v" }}.
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"env":        os.Getenv,
+		"default":    defaultValue,
+		"upper":      strings.ToUpper,
+		"lower":      strings.ToLower,
+		"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"sha256":     sha256Hex,
+		"semverBump": semverBump,
+		"now":        now,
+		"join":       join,
+		"fromJSON":   fromJSON,
+		"fromYAML":   fromYAML,
+		"readFile":   readFile,
+		"base":       path.Base,
+	}
+}
+
+func defaultValue(def, value any) any {
+	if value == nil {
+		return def
+	}
+	if v := reflect.ValueOf(value); v.IsZero() || ((v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0) {
+		return def
+	}
+	return value
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// semverBump increments the major, minor or patch of a semantic version, keeping a leading v
+func semverBump(part, version string) (string, error) {
+	v := version
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+	if !semver.IsValid(v) {
+		return "", fmt.Errorf("not a semantic version: %q", version)
+	}
+	var major, minor, patch int
+	_, _ = fmt.Sscanf(semver.Canonical(v), "v%d.%d.%d", &major, &minor, &patch)
+	switch part {
+	case "major":
+		major, minor, patch = major+1, 0, 0
+	case "minor":
+		minor, patch = minor+1, 0
+	case "patch":
+		patch++
+	default:
+		return "", fmt.Errorf("unknown version part %q, expected major, minor or patch", part)
+	}
+	bumped := fmt.Sprintf("%d.%d.%d", major, minor, patch)
+	if strings.HasPrefix(version, "v") {
+		bumped = "v" + bumped
+	}
+	return bumped, nil
+}
+
+func now(layout ...string) string {
+	format := time.RFC3339
+	if len(layout) > 0 {
+		format = layout[0]
+	}
+	return time.Now().UTC().Format(format)
+}
+
+func join(sep string, list any) (string, error) {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", fmt.Errorf("join: not a list: %T", list)
+	}
+	parts := make([]string, v.Len())
+	for i := range parts {
+		parts[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	return strings.Join(parts, sep), nil
+}
+
+func fromJSON(s string) (any, error) {
+	var out any
+	err := json.Unmarshal([]byte(s), &out)
+	return out, err
+}
+
+func fromYAML(s string) (any, error) {
+	var out any
+	err := yaml.Unmarshal([]byte(s), &out)
+	return out, err
+}
+
+func readFile(path string) (string, error) {
+	contents, err := os.ReadFile(path)
+	return string(contents), err
+}
@@ -2,15 +2,22 @@ package template
 
 import (
 	"bytes"
+	"container/list"
+	"fmt"
 	"reflect"
+	"runtime"
+	"strings"
+	"sync"
 	"text/template"
 
 	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/internal/frames"
 )
 
 // Globals is a map of template variables available in all Render calls.
 // Built-in variables include ToolDir, RootDir, OS, and Arch. Packages can
-// add their own globals (e.g., git package adds GitRoot).
+// add their own globals (e.g., git package adds GitRoot). Globals override
+// the built-in functions of Funcs with the same name.
 //
 // To add custom variables:
 //
@@ -27,13 +34,19 @@ func init() {
 
 // Render processes a Go template string, substituting variables from Globals
 // and any additional context maps provided. Variables can be values or functions
-// (which are called during rendering).
+// (which are called during rendering), and the functions of Funcs are available.
+// With config.TemplateStrict, referencing a missing key is an error. Errors panic,
+// including the template and the location it was rendered from.
 //
 // Example:
 //
 //	Render("{{RootDir}}/.tool/{{OS}}_{{Arch}}")
 //	Render("Hello {{.Name}}", map[string]any{"Name": "World"})
+//	Render(`{{ env "VERSION" | default "dev" | trimPrefix "v" }}`)
 func Render(template string, args ...map[string]any) string {
+	if !strings.Contains(template, "{{") {
+		return template
+	}
 	context := map[string]any{}
 	for k, v := range Globals {
 		if reflect.TypeOf(v).Kind() != reflect.Func {
@@ -49,7 +62,7 @@ func Render(template string, args ...map[string]any) string {
 }
 
 func render(tpl string, context map[string]any) string {
-	funcs := template.FuncMap{}
+	funcs := Funcs()
 	for k, v := range Globals {
 		val := reflect.ValueOf(v)
 		switch val.Type().Kind() {
@@ -61,18 +74,119 @@ func render(tpl string, context map[string]any) string {
 			funcs[k] = func() any { return v }
 		}
 	}
-	t, err := template.New(tpl).Funcs(funcs).Parse(tpl)
+	t, err := parse(tpl, funcs)
 	if err != nil {
-		panic(err)
+		panic(renderError(tpl, err))
 	}
 	var buf bytes.Buffer
 	err = t.Execute(&buf, context)
 	if err != nil {
-		panic(err)
+		panic(renderError(tpl, err))
 	}
 	return buf.String()
 }
 
+type cacheKey struct {
+	template string
+	strict   bool
+}
+
+// maxParsed is the number of parsed templates cached; the least recently used are evicted, so
+// rendering dynamic strings, such as interpolated values, doesn't grow the cache without bound
+var maxParsed = 256
+
+var (
+	// parsed caches parsed templates, each is cloned to execute with the current functions
+	parsed      = map[cacheKey]*list.Element{} // values of parsedOrder
+	parsedOrder = list.New()                   // *parsedTemplate, most recently used first
+	parsedLock  = &sync.Mutex{}
+)
+
+type parsedTemplate struct {
+	key      cacheKey
+	template *template.Template
+}
+
+// cachedTemplate returns the cached parsed template, nil when not cached
+func cachedTemplate(key cacheKey) *template.Template {
+	parsedLock.Lock()
+	defer parsedLock.Unlock()
+	e, ok := parsed[key]
+	if !ok {
+		return nil
+	}
+	parsedOrder.MoveToFront(e)
+	return e.Value.(*parsedTemplate).template
+}
+
+// cacheTemplate caches the parsed template, evicting the least recently used beyond maxParsed
+func cacheTemplate(key cacheKey, t *template.Template) {
+	parsedLock.Lock()
+	defer parsedLock.Unlock()
+	if _, ok := parsed[key]; ok {
+		return
+	}
+	parsed[key] = parsedOrder.PushFront(&parsedTemplate{key: key, template: t})
+	for parsedOrder.Len() > maxParsed {
+		oldest := parsedOrder.Back()
+		parsedOrder.Remove(oldest)
+		delete(parsed, oldest.Value.(*parsedTemplate).key)
+	}
+}
+
+// parse returns the parsed template, from the cache when it was parsed recently
+func parse(tpl string, funcs template.FuncMap) (*template.Template, error) {
+	key := cacheKey{template: tpl, strict: config.TemplateStrict}
+	if t := cachedTemplate(key); t != nil {
+		clone, err := t.Clone()
+		if err != nil {
+			return nil, err
+		}
+		return clone.Funcs(funcs), nil
+	}
+	t := template.New(tpl).Funcs(funcs)
+	if key.strict {
+		t = t.Option("missingkey=error")
+	}
+	t, err := t.Parse(tpl)
+	if err != nil {
+		return nil, err
+	}
+	clone, err := t.Clone()
+	if err != nil {
+		return nil, err
+	}
+	cacheTemplate(key, t)
+	return clone, nil
+}
+
+// renderError describes a template error, with the location of the code that rendered it
+func renderError(tpl string, err error) error {
+	if location := callerLocation(); location != "" {
+		return fmt.Errorf("error rendering template %q from %s: %w", tpl, location, err)
+	}
+	return fmt.Errorf("error rendering template %q: %w", tpl, err)
+}
+
+// callerLocation returns the file and line of the first caller outside go-make and the template
+// packages, e.g. the build script line calling log.Info with a broken template
+func callerLocation() string {
+	pcs := make([]uintptr, 32)
+	callers := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := callers.Next()
+		if !frames.GoMake(frame.Function, frame.File) &&
+			!strings.HasPrefix(frame.Function, "text/template.") &&
+			!strings.HasPrefix(frame.Function, "reflect.") &&
+			!strings.HasPrefix(frame.Function, "runtime.") {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
 func renderFunc(template *string) func() string {
 	return func() string {
 		return Render(*template)
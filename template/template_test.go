@@ -0,0 +1,128 @@
+package template_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/anchore/go-make/config"
+	"github.com/anchore/go-make/lang"
+	"github.com/anchore/go-make/log"
+	"github.com/anchore/go-make/require"
+	"github.com/anchore/go-make/template"
+)
+
+func Test_Render(t *testing.T) {
+	require.SetAndRestore(t, &template.Globals, map[string]any{
+		"Name":    "value",
+		"Version": func() string { return "v1.2.3" },
+	})
+
+	require.Equal(t, "plain text", template.Render("plain text"))
+	require.Equal(t, "value v1.2.3 arg", template.Render("{{Name}} {{Version}} {{.Arg}}", map[string]any{"Arg": "arg"}))
+	require.Equal(t, "<no value>", template.Render("{{.Missing}}"))
+
+	// parsed templates are cached, the current globals are used each time
+	template.Globals["Version"] = func() string { return "v2.0.0" }
+	require.Equal(t, "value v2.0.0 arg", template.Render("{{Name}} {{Version}} {{.Arg}}", map[string]any{"Arg": "arg"}))
+}
+
+func Test_Render_cacheBounded(t *testing.T) {
+	require.SetAndRestore(t, template.MaxParsed, 3)
+
+	for i := range 10 {
+		require.Equal(t, fmt.Sprintf("value %d", i), template.Render(fmt.Sprintf("{{.Name}} %d", i), map[string]any{"Name": "value"}))
+	}
+	// dynamic templates don't grow the cache without bound
+	require.Equal(t, 3, template.ParsedLen())
+}
+
+func Test_Funcs(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.txt")
+	require.NoError(t, os.WriteFile(path, []byte("contents"), 0o600))
+	t.Setenv("TEMPLATE_TEST_VAR", "from-env")
+
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{template: `{{ env "TEMPLATE_TEST_VAR" }}`, expected: "from-env"},
+		{template: `{{ env "TEMPLATE_TEST_UNSET" | default "dev" }}`, expected: "dev"},
+		{template: `{{ .Value | default "dev" }}`, expected: "set"},
+		{template: `{{ .Empty | default "dev" }} {{ .Missing | default "dev" }}`, expected: "dev dev"},
+		{template: `{{ upper "abc" }} {{ lower "ABC" }}`, expected: "ABC abc"},
+		{template: `{{ "v1.2.3" | trimPrefix "v" }}`, expected: "1.2.3"},
+		{template: `{{ sha256 "abc" }}`, expected: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{template: `{{ semverBump "patch" "v1.2.3" }} {{ semverBump "minor" "1.2.3-rc.1" }} {{ semverBump "major" "v1.2.3" }}`, expected: "v1.2.4 1.3.0 v2.0.0"},
+		{template: `{{ join ", " .List }}`, expected: "a, b"},
+		{template: `{{ (fromJSON "{\"a\": [1, 2]}").a | join "-" }}`, expected: "1-2"},
+		{template: `{{ (fromYAML "name: go-make").name }}`, expected: "go-make"},
+		{template: `{{ readFile "` + filepath.ToSlash(path) + `" }}`, expected: "contents"},
+		{template: `{{ now "2006" }}`, expected: time.Now().UTC().Format("2006")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			got := template.Render(tt.template, map[string]any{
+				"Value": "set",
+				"Empty": "",
+				"List":  []string{"a", "b"},
+			})
+			require.Equal(t, tt.expected, got)
+		})
+	}
+
+	_, err := time.Parse(time.RFC3339, template.Render("{{ now }}"))
+	require.NoError(t, err)
+
+	for _, tpl := range []string{
+		`{{ semverBump "patch" "latest" }}`,
+		`{{ semverBump "build" "v1.2.3" }}`,
+		`{{ join "," "not a list" }}`,
+		`{{ fromJSON "{" }}`,
+		`{{ readFile "` + filepath.ToSlash(filepath.Join(dir, "missing")) + `" }}`,
+	} {
+		err := lang.Catch(func() {
+			template.Render(tpl)
+		})
+		require.Error(t, err)
+	}
+}
+
+func Test_Render_strict(t *testing.T) {
+	require.SetAndRestore(t, &config.TemplateStrict, true)
+
+	require.Equal(t, "value", template.Render("{{.Name}}", map[string]any{"Name": "value"}))
+
+	err := lang.Catch(func() {
+		template.Render("{{.Missing}}")
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `"{{.Missing}}"`)
+	require.Contains(t, err.Error(), "template_test.go:")
+	require.Contains(t, err.Error(), "map has no entry for key")
+}
+
+func Test_Render_errorsFromLog(t *testing.T) {
+	var location string
+	err := lang.Catch(func() {
+		_, file, line, _ := runtime.Caller(0)
+		location = fmt.Sprintf("%s:%d", file, line+2)
+		log.Info("{{ unknownFunc }}")
+	})
+	require.Error(t, err)
+	// reported at the caller of log.Info, rather than within go-make
+	require.Contains(t, err.Error(), "from "+location+":")
+}
+
+func Test_Render_errors(t *testing.T) {
+	err := lang.Catch(func() {
+		template.Render("{{ unknownFunc }}")
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `"{{ unknownFunc }}"`)
+	require.Contains(t, err.Error(), "template_test.go:")
+}
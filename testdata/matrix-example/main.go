@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+
+	. "github.com/anchore/go-make"
+	"github.com/anchore/go-make/run"
+	"github.com/anchore/go-make/template"
+)
+
+func main() {
+	Makefile(
+		Task{
+			Name:        "setup",
+			Description: "an example dependency of the matrix tasks",
+			Run: func() {
+				fmt.Println("setup ran")
+			},
+		},
+		Task{
+			Name:         "matrix-example",
+			Description:  "an example task run in parallel for each matrix combination",
+			Dependencies: Deps("setup"),
+			Matrix: map[string][]string{
+				"GOOS":   {"linux", "darwin"},
+				"GOARCH": {"amd64", "arm64"},
+			},
+			Parallel: true,
+			Run: func() {
+				env := Run("go env GOOS GOARCH", run.Quiet())
+				fmt.Printf("instance %s/%s env %q\n", template.Render("{{GOOS}}"), template.Render("{{GOARCH}}"), env)
+			},
+		},
+		Task{
+			Name:     "matrix-failure",
+			Matrix:   map[string][]string{"VALUE": {"ok", "fail"}},
+			Parallel: true,
+			Run: func() {
+				if template.Render("{{VALUE}}") == "fail" {
+					panic("failed instance")
+				}
+			},
+		},
+	)
+}